@@ -5,35 +5,73 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/amarbel-llc/purse-first/libs/go-mcp/server"
 	"github.com/amarbel-llc/purse-first/libs/go-mcp/transport"
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/metrics"
 	"github.com/friedenberg/grit/internal/tools"
 	intTransport "github.com/friedenberg/grit/internal/transport"
 )
 
 func main() {
 	sseMode := flag.Bool("sse", false, "Use HTTP/SSE transport instead of stdio")
-	port := flag.Int("port", 8080, "Port for HTTP/SSE transport")
+	httpMode := flag.Bool("http", false, "Use Streamable HTTP transport (single /mcp endpoint) instead of stdio")
+	port := flag.Int("port", 8080, "Port for HTTP/SSE and Streamable HTTP transports")
+	host := flag.String("host", "127.0.0.1", "Address to bind the HTTP transports to")
+	listenAddr := flag.String("listen", "", "Listen address for the HTTP transports, as host:port or unix:/path/to/socket (overrides --host and --port)")
+	tokenFile := flag.String("token-file", "", "File containing the bearer token HTTP clients must send (default: $GRIT_TOKEN)")
+	allowOrigins := flag.String("allow-origin", "", "Comma-separated browser origins allowed to connect (default: loopback origins only)")
+	sessionIdleTimeout := flag.Duration("session-idle-timeout", 30*time.Minute, "End Streamable HTTP sessions with no request or open stream for this long")
+	maxSessions := flag.Int("max-sessions", 100, "Maximum live Streamable HTTP sessions; further initialize requests get 503")
+	lockRetries := flag.Int("lock-retries", 5, "Times to retry a git command that fails because another git process holds a lock file")
+	lockRetryDelay := flag.Duration("lock-retry-delay", 100*time.Millisecond, "Wait before the first lock retry; doubles with each further retry")
+	timeout := flag.Duration("timeout", 2*time.Minute, "Default time limit for a tool call; 0 disables it")
+	toolTimeouts := flag.String("tool-timeout", "fetch=10m,pull=10m,push=10m", "Comma-separated per-tool time limits as tool=duration, overriding --timeout")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "grit — an MCP server exposing git operations\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n")
 		fmt.Fprintf(os.Stderr, "  grit [flags]\n\n")
-		fmt.Fprintf(os.Stderr, "Starts an MCP server on stdio (default) or HTTP/SSE.\n")
+		fmt.Fprintf(os.Stderr, "Starts an MCP server on stdio (default), HTTP/SSE, or Streamable HTTP.\n")
 		fmt.Fprintf(os.Stderr, "Intended to be launched by an MCP client such as Claude Code.\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
 		fmt.Fprintf(os.Stderr, "  grit                     # stdio transport\n")
 		fmt.Fprintf(os.Stderr, "  grit --sse --port 8080   # HTTP/SSE transport\n")
+		fmt.Fprintf(os.Stderr, "  grit --http --port 8080  # Streamable HTTP transport\n")
+		fmt.Fprintf(os.Stderr, "  GRIT_TOKEN=secret grit --http --host 0.0.0.0  # reachable from the network\n")
+		fmt.Fprintf(os.Stderr, "  grit --http --listen unix:/tmp/grit.sock      # Unix socket, no TCP port\n")
 	}
 
 	flag.Parse()
 
-	app := tools.RegisterAll()
+	perTool, err := parseToolTimeouts(*toolTimeouts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "grit: --tool-timeout: %v\n", err)
+		os.Exit(1)
+	}
+
+	app := tools.RegisterAll(git.LockRetryRunner{
+		Runner:  git.ExecRunner{},
+		Retries: *lockRetries,
+		Delay:   *lockRetryDelay,
+	}, tools.Timeouts{Default: *timeout, PerTool: perTool})
+
+	for name := range perTool {
+		if _, ok := app.GetCommand(name); !ok {
+			fmt.Fprintf(os.Stderr, "grit: --tool-timeout: unknown tool %q\n", name)
+			os.Exit(1)
+		}
+	}
 
 	if flag.NArg() == 2 && flag.Arg(0) == "generate-plugin" {
 		if err := app.GenerateAll(flag.Arg(1)); err != nil {
@@ -48,31 +86,93 @@ func main() {
 		os.Exit(1)
 	}
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	if *sseMode && *httpMode {
+		fmt.Fprintf(os.Stderr, "grit: --sse and --http cannot be combined\n")
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	var t transport.Transport
+	registry := server.NewToolRegistry()
+	app.RegisterMCPTools(registry)
+
+	provider := metrics.Default.InstrumentTools(registry)
+
+	newServer := func(ctx context.Context, t transport.Transport) (*server.Server, error) {
+		return server.New(intTransport.Cancellable(ctx, t, provider), server.Options{
+			ServerName:    app.Name,
+			ServerVersion: app.Version,
+			Tools:         provider,
+		})
+	}
+
+	token, err := intTransport.LoadToken(*tokenFile, "GRIT_TOKEN")
+	if err != nil {
+		log.Fatalf("loading token: %v", err)
+	}
+
+	httpOpts := intTransport.HTTPOptions{
+		Token:              token,
+		Ready:              git.CheckVersion,
+		Metrics:            metrics.Default.WriteText,
+		SessionIdleTimeout: *sessionIdleTimeout,
+		MaxSessions:        *maxSessions,
+	}
+	for _, origin := range strings.Split(*allowOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			httpOpts.AllowedOrigins = append(httpOpts.AllowedOrigins, origin)
+		}
+	}
+
+	addr := net.JoinHostPort(*host, strconv.Itoa(*port))
+	if *listenAddr != "" {
+		addr = *listenAddr
+	}
+
+	if (*sseMode || *httpMode) && token == "" && !isLocalAddr(addr) {
+		log.Printf("warning: listening on %s without a token; anyone who can reach it can run git commands", addr)
+	}
+
+	// Each HTTP session gets its own server so one client disconnecting
+	// does not affect the others.
+	serveSession := func(ctx context.Context, session intTransport.SessionTransport) {
+		srv, err := newServer(ctx, session)
+		if err != nil {
+			log.Printf("creating server for session %s: %v", session.ID(), err)
+			return
+		}
+
+		if err := srv.Run(ctx); err != nil && err != context.Canceled {
+			log.Printf("session %s: %v", session.ID(), err)
+		}
+	}
+
+	if *httpMode {
+		streamable := intTransport.NewStreamableHTTP(addr, serveSession, httpOpts)
+		if err := streamable.Start(ctx); err != nil {
+			log.Fatalf("starting Streamable HTTP transport: %v", err)
+		}
+		defer streamable.Close()
+		log.Printf("Streamable HTTP transport listening on %s (endpoint /mcp)", streamable.Addr())
+
+		<-ctx.Done()
+		return
+	}
 
 	if *sseMode {
-		sse := intTransport.NewSSE(fmt.Sprintf(":%d", *port))
+		sse := intTransport.NewSSE(addr, serveSession, httpOpts)
 		if err := sse.Start(ctx); err != nil {
 			log.Fatalf("starting SSE transport: %v", err)
 		}
 		defer sse.Close()
 		log.Printf("SSE transport listening on %s", sse.Addr())
-		t = sse
-	} else {
-		t = transport.NewStdio(os.Stdin, os.Stdout)
-	}
 
-	registry := server.NewToolRegistry()
-	app.RegisterMCPTools(registry)
+		<-ctx.Done()
+		return
+	}
 
-	srv, err := server.New(t, server.Options{
-		ServerName:    app.Name,
-		ServerVersion: app.Version,
-		Tools:         registry,
-	})
+	srv, err := newServer(ctx, transport.NewStdio(os.Stdin, os.Stdout))
 	if err != nil {
 		log.Fatalf("creating server: %v", err)
 	}
@@ -81,3 +181,49 @@ func main() {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+// parseToolTimeouts parses a comma-separated list of tool=duration pairs.
+func parseToolTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not tool=duration", pair)
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		timeouts[strings.TrimSpace(name)] = d
+	}
+
+	return timeouts, nil
+}
+
+// isLocalAddr reports whether a listen address only accepts connections
+// from this machine.
+func isLocalAddr(addr string) bool {
+	if strings.HasPrefix(addr, "unix:") {
+		return true
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,263 @@
+package git
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// BudgetPatch spreads a budget of budgetLines patch lines across the files
+// of a raw patch instead of cutting it after the first N lines. Files that
+// fit in their share are kept whole, larger files are cut at a hunk
+// boundary, and files where not even one hunk fits are summarised. Files
+// matching an exclude glob are always summarised and do not use budget. If
+// budgetLines is 0, only the excludes are applied.
+//
+// Returns the budgeted patch and one FileTruncation for every file that is
+// not shown in full.
+func BudgetPatch(patch string, budgetLines int, exclude []string) (string, []FileTruncation) {
+	chunks := splitPatchFiles(patch)
+
+	var included []int
+	var sizes []int
+	truncations := make([]*FileTruncation, len(chunks))
+
+	files := make([]PatchFile, len(chunks))
+	for i, chunk := range chunks {
+		if parsed := ParsePatch(strings.Join(chunk, "\n")); len(parsed) > 0 {
+			files[i] = parsed[0]
+		}
+
+		if matchesAnyGlob(files[i].Path, exclude) {
+			truncations[i] = newFileTruncation(files[i], "excluded", 0, len(chunk))
+			chunks[i] = nil
+			continue
+		}
+
+		included = append(included, i)
+		sizes = append(sizes, len(chunk))
+	}
+
+	allocations := allocateBudget(sizes, budgetLines)
+
+	for j, i := range included {
+		chunk := chunks[i]
+		if allocations[j] >= len(chunk) {
+			continue
+		}
+
+		// Cut points are the start of every hunk after the first and the
+		// end of the chunk; cutting before the first hunk leaves only the
+		// file header, which is not worth showing.
+		var cuts []int
+		for n, line := range chunk {
+			if strings.HasPrefix(line, "@@ ") {
+				cuts = append(cuts, n)
+			}
+		}
+		cuts = append(cuts, len(chunk))
+
+		kept := 0
+		if len(cuts) > 1 {
+			kept = lastCutWithin(cuts[1:], allocations[j])
+		}
+
+		if kept == 0 {
+			truncations[i] = newFileTruncation(files[i], "summarized", 0, len(chunk))
+			chunks[i] = nil
+			continue
+		}
+
+		truncations[i] = newFileTruncation(files[i], "truncated", kept, len(chunk))
+		chunks[i] = chunk[:kept]
+	}
+
+	var out []string
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+
+	if len(out) == 0 {
+		return "", compactTruncations(truncations)
+	}
+
+	return strings.Join(out, "\n") + "\n", compactTruncations(truncations)
+}
+
+// BudgetFiles is BudgetPatch for structured patches. A file's size is the
+// number of hunk headers and hunk lines it has, matching TruncateFiles.
+// Summarised and excluded files are kept with their metadata but no hunks.
+func BudgetFiles(files []PatchFile, budgetLines int, exclude []string) ([]PatchFile, []FileTruncation) {
+	var included []int
+	var sizes []int
+	truncations := make([]*FileTruncation, len(files))
+
+	kept := make([]PatchFile, len(files))
+	copy(kept, files)
+
+	for i, file := range files {
+		size := 0
+		for _, hunk := range file.Hunks {
+			size += 1 + len(hunk.Lines)
+		}
+
+		if matchesAnyGlob(file.Path, exclude) {
+			truncations[i] = newFileTruncation(file, "excluded", 0, size)
+			kept[i].Hunks = []PatchHunk{}
+			continue
+		}
+
+		included = append(included, i)
+		sizes = append(sizes, size)
+	}
+
+	allocations := allocateBudget(sizes, budgetLines)
+
+	for j, i := range included {
+		if allocations[j] >= sizes[j] {
+			continue
+		}
+
+		file := files[i]
+
+		var cuts []int
+		total := 0
+		for _, hunk := range file.Hunks {
+			total += 1 + len(hunk.Lines)
+			cuts = append(cuts, total)
+		}
+
+		shown := lastCutWithin(cuts, allocations[j])
+
+		hunks := []PatchHunk{}
+		for n, cut := range cuts {
+			if cut > shown {
+				break
+			}
+			hunks = append(hunks, file.Hunks[n])
+		}
+		kept[i].Hunks = hunks
+
+		status := "truncated"
+		if shown == 0 {
+			status = "summarized"
+		}
+
+		truncations[i] = newFileTruncation(file, status, shown, sizes[j])
+	}
+
+	return kept, compactTruncations(truncations)
+}
+
+// allocateBudget splits budget across files of the given sizes so that
+// small files are shown whole and the rest share what is left equally. A
+// budget of 0 or less allocates every file its full size.
+func allocateBudget(sizes []int, budget int) []int {
+	allocations := make([]int, len(sizes))
+
+	if budget <= 0 {
+		copy(allocations, sizes)
+		return allocations
+	}
+
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sizes[order[a]] < sizes[order[b]]
+	})
+
+	remaining := budget
+	for n, i := range order {
+		share := remaining / (len(order) - n)
+		allocations[i] = min(sizes[i], share)
+		remaining -= allocations[i]
+	}
+
+	return allocations
+}
+
+// lastCutWithin returns the largest cut point that is at most limit, or 0.
+// cuts must be ascending.
+func lastCutWithin(cuts []int, limit int) int {
+	best := 0
+	for _, cut := range cuts {
+		if cut > limit {
+			break
+		}
+		best = cut
+	}
+	return best
+}
+
+// splitPatchFiles splits a raw patch into the lines of each file, starting
+// at every "diff --git" line.
+func splitPatchFiles(patch string) [][]string {
+	var chunks [][]string
+
+	if patch == "" {
+		return chunks
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if strings.HasPrefix(line, "diff --git ") || len(chunks) == 0 {
+			chunks = append(chunks, nil)
+		}
+		chunks[len(chunks)-1] = append(chunks[len(chunks)-1], line)
+	}
+
+	return chunks
+}
+
+// matchesAnyGlob reports whether p matches one of the glob patterns.
+// Patterns without a slash are matched against the file name in any
+// directory, so "*.lock" matches "web/yarn.lock".
+func matchesAnyGlob(p string, patterns []string) bool {
+	for _, pattern := range patterns {
+		target := p
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(p)
+		}
+
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compactTruncations drops the files that were shown in full, keeping the
+// rest in patch order.
+func compactTruncations(byFile []*FileTruncation) []FileTruncation {
+	truncations := []FileTruncation{}
+	for _, tr := range byFile {
+		if tr != nil {
+			truncations = append(truncations, *tr)
+		}
+	}
+	return truncations
+}
+
+func newFileTruncation(file PatchFile, status string, shown, total int) *FileTruncation {
+	stat := DiffStat{Path: file.Path, Binary: file.Binary}
+	for _, hunk := range file.Hunks {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case "added":
+				stat.Additions++
+			case "deleted":
+				stat.Deletions++
+			}
+		}
+	}
+
+	return &FileTruncation{
+		Path:       file.Path,
+		Status:     status,
+		ShownLines: shown,
+		TotalLines: total,
+		Stat:       stat,
+	}
+}
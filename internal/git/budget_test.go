@@ -0,0 +1,173 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// budgetTestPatch builds a patch with a small two-hunk file "a.go", a large
+// "yarn.lock" and a small "b.go", in that order.
+func budgetTestPatch() string {
+	var b strings.Builder
+
+	b.WriteString("diff --git a/a.go b/a.go\nindex 1111111..2222222 100644\n--- a/a.go\n+++ b/a.go\n")
+	b.WriteString("@@ -1,2 +1,2 @@\n-x\n+y\n z\n")
+	b.WriteString("@@ -10,1 +10,2 @@\n w\n+v\n")
+
+	b.WriteString("diff --git a/web/yarn.lock b/web/yarn.lock\nindex 3333333..4444444 100644\n--- a/web/yarn.lock\n+++ b/web/yarn.lock\n")
+	for h := 0; h < 5; h++ {
+		fmt.Fprintf(&b, "@@ -%d,0 +%d,10 @@\n", h*100, h*100+1)
+		for i := 0; i < 10; i++ {
+			fmt.Fprintf(&b, "+dep%d-%d\n", h, i)
+		}
+	}
+
+	b.WriteString("diff --git a/b.go b/b.go\nindex 5555555..6666666 100644\n--- a/b.go\n+++ b/b.go\n")
+	b.WriteString("@@ -1 +1 @@\n-old\n+new\n")
+
+	return b.String()
+}
+
+func TestBudgetPatch(t *testing.T) {
+	patch := budgetTestPatch()
+
+	// a.go is 11 lines, yarn.lock 59, b.go 7. With 40 lines, a.go and b.go
+	// fit and yarn.lock gets the remaining 22, which holds one 11-line hunk
+	// after its 4 header lines.
+	got, truncations := BudgetPatch(patch, 40, nil)
+
+	if !strings.Contains(got, "+y") || !strings.Contains(got, "+new") {
+		t.Errorf("small files missing from budgeted patch:\n%s", got)
+	}
+	if !strings.Contains(got, "+dep0-9") || strings.Contains(got, "+dep1-0") {
+		t.Errorf("yarn.lock not cut after its first hunk:\n%s", got)
+	}
+	if !strings.HasPrefix(got, "diff --git a/a.go") || !strings.HasSuffix(got, "+new\n") {
+		t.Errorf("file order not preserved:\n%s", got)
+	}
+
+	if len(truncations) != 1 {
+		t.Fatalf("truncations = %+v, want 1", truncations)
+	}
+
+	tr := truncations[0]
+	if tr.Path != "web/yarn.lock" || tr.Status != "truncated" || tr.ShownLines != 15 || tr.TotalLines != 59 {
+		t.Errorf("truncation = %+v, want web/yarn.lock truncated 15/59", tr)
+	}
+	if tr.Stat.Additions != 50 || tr.Stat.Deletions != 0 {
+		t.Errorf("stat = %+v, want 50 additions", tr.Stat)
+	}
+}
+
+func TestBudgetPatchSummarizesWhenNoHunkFits(t *testing.T) {
+	got, truncations := BudgetPatch(budgetTestPatch(), 30, nil)
+
+	if strings.Contains(got, "yarn.lock") {
+		t.Errorf("yarn.lock should be summarised, got:\n%s", got)
+	}
+
+	if len(truncations) != 1 || truncations[0].Status != "summarized" || truncations[0].ShownLines != 0 {
+		t.Errorf("truncations = %+v, want yarn.lock summarized", truncations)
+	}
+}
+
+func TestBudgetPatchExclude(t *testing.T) {
+	got, truncations := BudgetPatch(budgetTestPatch(), 0, []string{"*.lock"})
+
+	if strings.Contains(got, "yarn.lock") {
+		t.Errorf("excluded file in patch:\n%s", got)
+	}
+	if !strings.Contains(got, "+y") || !strings.Contains(got, "+new") {
+		t.Errorf("other files missing:\n%s", got)
+	}
+
+	if len(truncations) != 1 || truncations[0].Status != "excluded" || truncations[0].Path != "web/yarn.lock" {
+		t.Errorf("truncations = %+v, want web/yarn.lock excluded", truncations)
+	}
+}
+
+func TestBudgetPatchUnlimited(t *testing.T) {
+	patch := budgetTestPatch()
+
+	got, truncations := BudgetPatch(patch, 0, nil)
+	if got != patch {
+		t.Errorf("unlimited budget changed the patch")
+	}
+	if truncations == nil || len(truncations) != 0 {
+		t.Errorf("truncations = %v, want empty slice", truncations)
+	}
+}
+
+func TestBudgetFiles(t *testing.T) {
+	files := ParsePatch(budgetTestPatch())
+
+	// Structured sizes: a.go 7, yarn.lock 55, b.go 3; 21 lines leaves 11
+	// for yarn.lock, exactly one hunk.
+	got, truncations := BudgetFiles(files, 21, nil)
+
+	if len(got) != 3 {
+		t.Fatalf("files = %d, want 3", len(got))
+	}
+	if len(got[0].Hunks) != 2 || len(got[1].Hunks) != 1 || len(got[2].Hunks) != 1 {
+		t.Errorf("hunk counts = %d, %d, %d, want 2, 1, 1", len(got[0].Hunks), len(got[1].Hunks), len(got[2].Hunks))
+	}
+	if len(files[1].Hunks) != 5 {
+		t.Errorf("input was modified: yarn.lock hunks = %d, want 5", len(files[1].Hunks))
+	}
+
+	if len(truncations) != 1 || truncations[0].Status != "truncated" || truncations[0].ShownLines != 11 || truncations[0].TotalLines != 55 {
+		t.Errorf("truncations = %+v, want yarn.lock truncated 11/55", truncations)
+	}
+
+	got, truncations = BudgetFiles(files, 0, []string{"web/*.lock"})
+	if len(got[1].Hunks) != 0 || len(truncations) != 1 || truncations[0].Status != "excluded" {
+		t.Errorf("exclude: hunks = %d, truncations = %+v, want yarn.lock excluded", len(got[1].Hunks), truncations)
+	}
+}
+
+func TestAllocateBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		sizes  []int
+		budget int
+		want   []int
+	}{
+		{name: "unlimited", sizes: []int{5, 50}, budget: 0, want: []int{5, 50}},
+		{name: "all fit", sizes: []int{5, 10}, budget: 20, want: []int{5, 10}},
+		{name: "small files first", sizes: []int{100, 5, 100}, budget: 45, want: []int{20, 5, 20}},
+		{name: "equal split", sizes: []int{30, 30}, budget: 20, want: []int{10, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateBudget(tt.sizes, tt.budget)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("allocation = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMatchesAnyGlob(t *testing.T) {
+	tests := []struct {
+		path     string
+		patterns []string
+		want     bool
+	}{
+		{"web/yarn.lock", []string{"*.lock"}, true},
+		{"web/yarn.lock", []string{"web/*"}, true},
+		{"web/yarn.lock", []string{"*/other"}, false},
+		{"snap/__snapshots__/a.snap", []string{"*.snap"}, true},
+		{"main.go", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesAnyGlob(tt.path, tt.patterns); got != tt.want {
+			t.Errorf("matchesAnyGlob(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/output"
+)
+
+// ErrorKind classifies why a git invocation failed, so callers can branch on
+// the cause without matching git's English messages themselves.
+type ErrorKind string
+
+const (
+	KindNotARepository         ErrorKind = "not_a_repository"
+	KindUnknownRevision        ErrorKind = "unknown_revision"
+	KindMergeConflict          ErrorKind = "merge_conflict"
+	KindNonFastForward         ErrorKind = "non_fast_forward"
+	KindAuthenticationRequired ErrorKind = "authentication_required"
+	KindLockFileExists         ErrorKind = "lock_file_exists"
+	KindDirtyWorktree          ErrorKind = "dirty_worktree"
+	KindNothingToCommit        ErrorKind = "nothing_to_commit"
+	KindPathspecNoMatch        ErrorKind = "pathspec_no_match"
+	KindAlreadyExists          ErrorKind = "already_exists"
+	KindNoUpstream             ErrorKind = "no_upstream"
+	KindNetwork                ErrorKind = "network"
+	KindTimedOut               ErrorKind = "timed_out"
+	KindCancelled              ErrorKind = "cancelled"
+	KindUnknown                ErrorKind = "unknown"
+)
+
+// Error is a failed git invocation. Stderr and Stdout hold git's complete
+// output; Error() only includes a bounded excerpt of stderr.
+type Error struct {
+	Args     []string
+	ExitCode int // -1 if git did not run to completion
+	Stderr   string
+	Stdout   string
+	Kind     ErrorKind
+	Lock     *LockInfo // set for KindLockFileExists when the lock was inspected
+	Err      error     // the underlying exec error
+}
+
+func (e *Error) Error() string {
+	limited := output.LimitStderr(e.Stderr)
+	msg := fmt.Sprintf("git %v: %v: %s", e.Args, e.Err, limited.Content)
+	if e.Lock != nil {
+		msg = strings.TrimRight(msg, "\n") + "\n" + e.Lock.String()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of the *Error in err's chain, or KindUnknown if
+// there is none.
+func KindOf(err error) ErrorKind {
+	var gitErr *Error
+	if errors.As(err, &gitErr) {
+		return gitErr.Kind
+	}
+	return KindUnknown
+}
+
+// errorPatterns maps lowercased fragments of git's output to the kind they
+// indicate. Earlier entries win, so more specific causes come first: a
+// rejected SSH key also prints "could not read from remote repository".
+var errorPatterns = []struct {
+	kind      ErrorKind
+	fragments []string
+}{
+	{KindLockFileExists, []string{
+		".lock': file exists",
+		"another git process seems to be running",
+	}},
+	{KindNotARepository, []string{
+		"not a git repository",
+	}},
+	{KindMergeConflict, []string{
+		"conflict (",
+		"could not apply",
+		"fix conflicts",
+		"still have conflicts",
+		"unmerged paths",
+		"unmerged files",
+		"resolve your current index first",
+		"automatic merge failed",
+	}},
+	{KindDirtyWorktree, []string{
+		"would be overwritten by",
+		"please commit your changes or stash them",
+		"you have unstaged changes",
+		"your index contains uncommitted changes",
+		"contains modified or untracked files",
+	}},
+	{KindNonFastForward, []string{
+		"non-fast-forward",
+		"[rejected]",
+		"not possible to fast-forward",
+		"diverging branches",
+		"updates were rejected",
+	}},
+	{KindAuthenticationRequired, []string{
+		"authentication failed",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"permission denied (publickey",
+		"host key verification failed",
+	}},
+	{KindNetwork, []string{
+		"could not resolve host",
+		"connection refused",
+		"connection timed out",
+		"network is unreachable",
+		"unable to access",
+		"could not read from remote repository",
+	}},
+	{KindNoUpstream, []string{
+		"no upstream configured",
+		"has no upstream branch",
+		"no tracking information",
+	}},
+	{KindPathspecNoMatch, []string{
+		"did not match any file(s) known to git",
+		"did not match any files",
+	}},
+	{KindUnknownRevision, []string{
+		"unknown revision",
+		"bad revision",
+		"ambiguous argument",
+		"not a valid object name",
+		"invalid reference",
+		"needed a single revision",
+		"bad object",
+		"no such ref",
+		"not a commit",
+	}},
+	{KindAlreadyExists, []string{
+		"already exists",
+	}},
+	{KindNothingToCommit, []string{
+		"nothing to commit",
+		"nothing added to commit",
+		"no changes added to commit",
+	}},
+}
+
+// ContextErrorKind returns the kind for a call stopped because its context
+// was done, or "" if err is neither a deadline nor a cancellation.
+func ContextErrorKind(err error) ErrorKind {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimedOut
+	case errors.Is(err, context.Canceled):
+		return KindCancelled
+	default:
+		return ""
+	}
+}
+
+// ClassifyError works out the kind of a failure from git's stderr and
+// stdout. Some messages, such as merge CONFLICT lines, go to stdout.
+func ClassifyError(stderr, stdout string) ErrorKind {
+	text := strings.ToLower(stderr + "\n" + stdout)
+
+	for _, p := range errorPatterns {
+		for _, fragment := range p.fragments {
+			if strings.Contains(text, fragment) {
+				return p.kind
+			}
+		}
+	}
+
+	return KindUnknown
+}
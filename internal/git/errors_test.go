@@ -0,0 +1,154 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		stdout string
+		want   ErrorKind
+	}{
+		{
+			name:   "not a repository",
+			stderr: "fatal: not a git repository (or any of the parent directories): .git\n",
+			want:   KindNotARepository,
+		},
+		{
+			name:   "unknown revision",
+			stderr: "fatal: ambiguous argument 'nope': unknown revision or path not in the working tree.\n",
+			want:   KindUnknownRevision,
+		},
+		{
+			name:   "bad revision",
+			stderr: "fatal: bad revision 'nope'\n",
+			want:   KindUnknownRevision,
+		},
+		{
+			name:   "merge conflict on stdout",
+			stdout: "Auto-merging a.txt\nCONFLICT (content): Merge conflict in a.txt\nAutomatic merge failed; fix conflicts and then commit the result.\n",
+			want:   KindMergeConflict,
+		},
+		{
+			name:   "rebase could not apply",
+			stderr: "error: could not apply 1a2b3c4... change a\nhint: Resolve all conflicts manually\n",
+			want:   KindMergeConflict,
+		},
+		{
+			name:   "non-fast-forward push",
+			stderr: " ! [rejected]        main -> main (fetch first)\nerror: failed to push some refs to 'origin'\n",
+			want:   KindNonFastForward,
+		},
+		{
+			name:   "https authentication",
+			stderr: "fatal: could not read Username for 'https://example.com': terminal prompts disabled\n",
+			want:   KindAuthenticationRequired,
+		},
+		{
+			name:   "ssh key rejected",
+			stderr: "git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.\n",
+			want:   KindAuthenticationRequired,
+		},
+		{
+			name:   "unreachable remote",
+			stderr: "fatal: unable to access 'https://example.invalid/': Could not resolve host: example.invalid\n",
+			want:   KindNetwork,
+		},
+		{
+			name:   "index lock",
+			stderr: "fatal: Unable to create '/repo/.git/index.lock': File exists.\n\nAnother git process seems to be running in this repository\n",
+			want:   KindLockFileExists,
+		},
+		{
+			name:   "dirty worktree",
+			stderr: "error: Your local changes to the following files would be overwritten by checkout:\n\ta.txt\nPlease commit your changes or stash them before you switch branches.\n",
+			want:   KindDirtyWorktree,
+		},
+		{
+			name:   "rebase with unstaged changes",
+			stderr: "error: cannot rebase: You have unstaged changes.\n",
+			want:   KindDirtyWorktree,
+		},
+		{
+			name:   "nothing to commit",
+			stdout: "On branch main\nnothing to commit, working tree clean\n",
+			want:   KindNothingToCommit,
+		},
+		{
+			name:   "pathspec",
+			stderr: "fatal: pathspec 'missing.txt' did not match any files\n",
+			want:   KindPathspecNoMatch,
+		},
+		{
+			name:   "branch exists",
+			stderr: "fatal: a branch named 'feature' already exists\n",
+			want:   KindAlreadyExists,
+		},
+		{
+			name:   "no upstream",
+			stderr: "fatal: no upstream configured for branch 'feature'\n",
+			want:   KindNoUpstream,
+		},
+		{
+			name:   "unrecognised",
+			stderr: "fatal: something new went wrong\n",
+			want:   KindUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.stderr, tt.stdout); got != tt.want {
+				t.Errorf("ClassifyError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecRunnerReturnsError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	_, err := Run(t.Context(), t.TempDir(), "rev-parse", "HEAD")
+
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("err = %T (%v), want *Error", err, err)
+	}
+
+	if gitErr.Kind != KindNotARepository {
+		t.Errorf("Kind = %q, want %q", gitErr.Kind, KindNotARepository)
+	}
+	if gitErr.ExitCode != 128 {
+		t.Errorf("ExitCode = %d, want 128", gitErr.ExitCode)
+	}
+	if len(gitErr.Args) != 2 || gitErr.Args[0] != "rev-parse" {
+		t.Errorf("Args = %v, want [rev-parse HEAD]", gitErr.Args)
+	}
+	if !strings.Contains(gitErr.Stderr, "not a git repository") {
+		t.Errorf("Stderr = %q, want not a git repository", gitErr.Stderr)
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Errorf("err does not unwrap to *exec.ExitError")
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	wrapped := fmt.Errorf("rebasing: %w", &Error{Kind: KindMergeConflict})
+	if got := KindOf(wrapped); got != KindMergeConflict {
+		t.Errorf("KindOf(wrapped) = %q, want %q", got, KindMergeConflict)
+	}
+
+	if got := KindOf(errors.New("plain")); got != KindUnknown {
+		t.Errorf("KindOf(plain) = %q, want %q", got, KindUnknown)
+	}
+}
@@ -3,40 +3,154 @@ package git
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
-	"github.com/amarbel-llc/purse-first/libs/go-mcp/output"
+	"github.com/friedenberg/grit/internal/metrics"
 )
 
-func Run(ctx context.Context, dir string, args ...string) (string, error) {
-	if strings.ContainsRune(dir, 0) {
-		return "", fmt.Errorf("dir contains null byte")
-	}
+// killGracePeriod is how long a cancelled git has to exit after being
+// signalled before it is killed outright.
+const killGracePeriod = 2 * time.Second
 
-	for _, arg := range args {
-		if strings.ContainsRune(arg, 0) {
-			return "", fmt.Errorf("argument contains null byte")
-		}
-	}
+// Invocation is one run of git.
+type Invocation struct {
+	Dir   string
+	Env   []string
+	Stdin string
+	Args  []string
+}
+
+// Runner runs git invocations and returns their stdout. ExecRunner runs the
+// real git binary; tests substitute a fake (see the gittest package).
+type Runner interface {
+	Run(ctx context.Context, inv Invocation) (string, error)
+}
+
+// ExecRunner runs git as a subprocess.
+type ExecRunner struct{}
 
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = dir
+// Run implements Runner. Extra environment variables (e.g.
+// GIT_SEQUENCE_EDITOR) are appended after the defaults, so they take
+// precedence.
+func (ExecRunner) Run(ctx context.Context, inv Invocation) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", inv.Args...)
+	cmd.Dir = inv.Dir
 	cmd.Env = append(os.Environ(),
 		"GIT_TERMINAL_PROMPT=0",
 		"GIT_EDITOR=true",
 	)
+	cmd.Env = append(cmd.Env, inv.Env...)
+
+	// Cancelling signals git's whole process group, so helpers it spawns
+	// (ssh, credential helpers, hooks) stop too, then gives them
+	// killGracePeriod to exit before the pipes are closed regardless.
+	setProcessGroup(cmd)
+	cmd.WaitDelay = killGracePeriod
+
+	if inv.Stdin != "" {
+		cmd.Stdin = strings.NewReader(inv.Stdin)
+	}
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		limited := output.LimitStderr(stderr.String())
-		return "", fmt.Errorf("git %v: %w: %s", args, err, limited.Content)
+	start := time.Now()
+	err := cmd.Run()
+	metrics.Default.ObserveGit(subcommand(inv.Args), time.Since(start), err != nil)
+
+	if err != nil {
+		gitErr := newError(inv.Args, err, stdout.String(), stderr.String())
+		if kind := ContextErrorKind(ctx.Err()); kind != "" {
+			gitErr.Kind = kind
+			gitErr.Err = ctx.Err()
+		}
+		return "", gitErr
 	}
 
 	return stdout.String(), nil
 }
+
+// newError builds the *Error for a failed invocation, taking the exit code
+// from an *exec.ExitError when git ran to completion.
+func newError(args []string, err error, stdout, stderr string) *Error {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	return &Error{
+		Args:     args,
+		ExitCode: exitCode,
+		Stderr:   stderr,
+		Stdout:   stdout,
+		Kind:     ClassifyError(stderr, stdout),
+		Err:      err,
+	}
+}
+
+type runnerKey struct{}
+
+// WithRunner returns a context whose git invocations go through r.
+func WithRunner(ctx context.Context, r Runner) context.Context {
+	return context.WithValue(ctx, runnerKey{}, r)
+}
+
+// RunnerFrom returns the Runner attached to ctx, or ExecRunner if none is.
+func RunnerFrom(ctx context.Context) Runner {
+	if r, ok := ctx.Value(runnerKey{}).(Runner); ok {
+		return r
+	}
+	return ExecRunner{}
+}
+
+func Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return RunInvocation(ctx, Invocation{Dir: dir, Args: args})
+}
+
+// RunEnv is Run with extra environment variables (e.g. GIT_SEQUENCE_EDITOR)
+// appended after the defaults, so they take precedence.
+func RunEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	return RunInvocation(ctx, Invocation{Dir: dir, Env: env, Args: args})
+}
+
+// RunInput is Run with stdin fed from input.
+func RunInput(ctx context.Context, dir, input string, args ...string) (string, error) {
+	return RunInvocation(ctx, Invocation{Dir: dir, Stdin: input, Args: args})
+}
+
+// RunInvocation validates inv and runs it with the context's Runner.
+func RunInvocation(ctx context.Context, inv Invocation) (string, error) {
+	if strings.ContainsRune(inv.Dir, 0) {
+		return "", fmt.Errorf("dir contains null byte")
+	}
+
+	for _, arg := range inv.Args {
+		if strings.ContainsRune(arg, 0) {
+			return "", fmt.Errorf("argument contains null byte")
+		}
+	}
+
+	return RunnerFrom(ctx).Run(ctx, inv)
+}
+
+// subcommand returns the git subcommand of args, skipping global options
+// such as -C <dir> or -c <key>=<value>.
+func subcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-C" || args[i] == "-c":
+			i++
+		case strings.HasPrefix(args[i], "-"):
+		default:
+			return args[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+package git
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestExecRunnerTimeoutKillsProcessGroup(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	// The alias runs sleep as a grandchild holding git's stdout open, so
+	// Run only returns promptly if the whole process group is signalled.
+	start := time.Now()
+	_, err := Run(ctx, t.TempDir(), "-c", "alias.hang=!sleep 30", "hang")
+	elapsed := time.Since(start)
+
+	if elapsed >= killGracePeriod {
+		t.Errorf("Run took %s, want the group killed well before %s", elapsed, killGracePeriod)
+	}
+
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("err = %v, want *Error", err)
+	}
+	if gitErr.Kind != KindTimedOut {
+		t.Errorf("Kind = %q, want %q", gitErr.Kind, KindTimedOut)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded in chain", err)
+	}
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitPath resolves name inside the git directory of the repository or
+// worktree at dir. In a linked worktree .git is a file, and per-worktree
+// state such as rebase-merge or MERGE_HEAD lives under the main repository's
+// worktrees/ directory, so paths must not be built from dir/.git directly.
+func GitPath(ctx context.Context, dir, name string) (string, error) {
+	out, err := Run(ctx, dir, "rev-parse", "--git-path", name)
+	if err != nil {
+		return "", err
+	}
+
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	return path, nil
+}
+
+// GitPathExists reports whether name exists inside the git directory of the
+// repository or worktree at dir.
+func GitPathExists(ctx context.Context, dir, name string) bool {
+	path, err := GitPath(ctx, dir, name)
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// ResolveGitDir finds the git directory of the repository or worktree
+// containing dir by walking up to the nearest .git, without running git. In
+// a linked worktree this is the worktree's own directory under the main
+// repository's worktrees/, which holds its index and index.lock.
+func ResolveGitDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for d := abs; ; d = filepath.Dir(d) {
+		if gitDir, ok := dotGit(d); ok {
+			if resolved, err := filepath.EvalSymlinks(gitDir); err == nil {
+				gitDir = resolved
+			}
+			return gitDir, nil
+		}
+
+		if filepath.Dir(d) == d {
+			return "", fmt.Errorf("%s is not in a git repository", dir)
+		}
+	}
+}
+
+// dotGit returns the git directory d/.git points at, or d itself for a
+// bare repository.
+func dotGit(d string) (string, bool) {
+	path := filepath.Join(d, ".git")
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return path, true
+	case err == nil:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false
+		}
+
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return "", false
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(d, target)
+		}
+		return filepath.Clean(target), true
+	}
+
+	if isFile(filepath.Join(d, "HEAD")) && isDir(filepath.Join(d, "objects")) {
+		return d, true
+	}
+
+	return "", false
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,144 @@
+// Package gittest provides a scripted git.Runner for testing code that runs
+// git without a real binary or repository.
+package gittest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// FakeRunner is a git.Runner that records every invocation and answers it
+// from a script of canned responses. Responses are matched against the
+// invocation's arguments in the order they were added; an invocation that
+// matches none fails the test.
+type FakeRunner struct {
+	t         TestingT
+	mu        sync.Mutex
+	responses []*Response
+	calls     []git.Invocation
+}
+
+// TestingT is the subset of testing.TB the fake reports failures through.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Response is a canned answer for invocations whose arguments start with
+// the arguments given to On.
+type Response struct {
+	prefix []string
+	output string
+	stderr string
+	fail   bool
+	times  int
+	used   int
+}
+
+// NewFakeRunner creates a runner with an empty script.
+func NewFakeRunner(t TestingT) *FakeRunner {
+	return &FakeRunner{t: t}
+}
+
+// On adds a response for invocations whose arguments start with args. It
+// succeeds with empty output until Return or Fail says otherwise.
+func (f *FakeRunner) On(args ...string) *Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := &Response{prefix: args}
+	f.responses = append(f.responses, r)
+	return r
+}
+
+// Return sets the output the invocation writes to stdout. Calling Fail
+// afterwards keeps it as the stdout of the failed run.
+func (r *Response) Return(output string) *Response {
+	r.output = output
+	r.fail = false
+	return r
+}
+
+// Fail makes the invocation fail the way a git exiting with status 1 does:
+// the error is a *git.Error whose kind is classified from stderr and any
+// output set by Return.
+func (r *Response) Fail(stderr string) *Response {
+	r.stderr = stderr
+	r.fail = true
+	return r
+}
+
+// Times limits the response to the next n matching invocations, after which
+// later responses in the script are tried. By default a response answers
+// every matching invocation.
+func (r *Response) Times(n int) *Response {
+	r.times = n
+	return r
+}
+
+// Run implements git.Runner.
+func (f *FakeRunner) Run(ctx context.Context, inv git.Invocation) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, inv)
+
+	for _, r := range f.responses {
+		if r.times > 0 && r.used >= r.times {
+			continue
+		}
+
+		if len(inv.Args) < len(r.prefix) || !slices.Equal(inv.Args[:len(r.prefix)], r.prefix) {
+			continue
+		}
+
+		r.used++
+		if r.fail {
+			return "", &git.Error{
+				Args:     inv.Args,
+				ExitCode: 1,
+				Stderr:   r.stderr,
+				Stdout:   r.output,
+				Kind:     git.ClassifyError(r.stderr, r.output),
+				Err:      errors.New("exit status 1"),
+			}
+		}
+		return r.output, nil
+	}
+
+	f.t.Helper()
+	f.t.Errorf("unexpected git invocation: git %s", strings.Join(inv.Args, " "))
+	return "", fmt.Errorf("git %v: unexpected invocation", inv.Args)
+}
+
+// Calls returns every invocation run so far, in order.
+func (f *FakeRunner) Calls() []git.Invocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.calls)
+}
+
+// Called reports whether any invocation's arguments start with args.
+func (f *FakeRunner) Called(args ...string) bool {
+	return f.Find(args...) != nil
+}
+
+// Find returns the first invocation whose arguments start with args, or nil.
+func (f *FakeRunner) Find(args ...string) *git.Invocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, inv := range f.calls {
+		if len(inv.Args) >= len(args) && slices.Equal(inv.Args[:len(args)], args) {
+			return &f.calls[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package gittest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFakeRunnerMatchesPrefixInOrder(t *testing.T) {
+	f := NewFakeRunner(t)
+	f.On("rev-parse", "--verify").Return("abc\n")
+	f.On("rev-parse").Return("def\n")
+
+	ctx := git.WithRunner(context.Background(), f)
+
+	out, err := git.Run(ctx, "/repo", "rev-parse", "--verify", "HEAD")
+	if err != nil || out != "abc\n" {
+		t.Errorf("Run = %q, %v, want %q", out, err, "abc\n")
+	}
+
+	out, err = git.Run(ctx, "/repo", "rev-parse", "HEAD")
+	if err != nil || out != "def\n" {
+		t.Errorf("Run = %q, %v, want %q", out, err, "def\n")
+	}
+
+	calls := f.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("len(Calls) = %d, want 2", len(calls))
+	}
+	if calls[0].Dir != "/repo" {
+		t.Errorf("Dir = %q, want %q", calls[0].Dir, "/repo")
+	}
+}
+
+func TestFakeRunnerFail(t *testing.T) {
+	f := NewFakeRunner(t)
+	f.On("commit").Fail("nothing to commit")
+
+	_, err := git.Run(git.WithRunner(context.Background(), f), "/repo", "commit", "-m", "x")
+	if err == nil || !strings.Contains(err.Error(), "nothing to commit") {
+		t.Errorf("err = %v, want nothing to commit", err)
+	}
+
+	var gitErr *git.Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("err = %T, want *git.Error", err)
+	}
+	if gitErr.ExitCode != 1 || gitErr.Kind != git.KindNothingToCommit {
+		t.Errorf("ExitCode, Kind = %d, %q, want 1, %q", gitErr.ExitCode, gitErr.Kind, git.KindNothingToCommit)
+	}
+}
+
+func TestFakeRunnerTimes(t *testing.T) {
+	f := NewFakeRunner(t)
+	f.On("status").Return("first").Times(1)
+	f.On("status").Return("rest")
+
+	ctx := git.WithRunner(context.Background(), f)
+
+	for _, want := range []string{"first", "rest", "rest"} {
+		if out, _ := git.Run(ctx, "", "status"); out != want {
+			t.Errorf("Run = %q, want %q", out, want)
+		}
+	}
+}
+
+func TestFakeRunnerRecordsEnvAndStdin(t *testing.T) {
+	f := NewFakeRunner(t)
+	f.On("apply")
+
+	ctx := git.WithRunner(context.Background(), f)
+	git.RunInput(ctx, "/repo", "patch", "apply", "--cached", "-")
+	git.RunEnv(ctx, "/repo", []string{"A=1"}, "apply", "--check")
+
+	inv := f.Find("apply", "--cached")
+	if inv == nil || inv.Stdin != "patch" {
+		t.Errorf("Find(apply --cached) = %+v, want stdin %q", inv, "patch")
+	}
+
+	inv = f.Find("apply", "--check")
+	if inv == nil || len(inv.Env) != 1 || inv.Env[0] != "A=1" {
+		t.Errorf("Find(apply --check) = %+v, want env A=1", inv)
+	}
+
+	if f.Called("commit") {
+		t.Error("Called(commit) = true, want false")
+	}
+}
+
+func TestFakeRunnerUnexpectedInvocation(t *testing.T) {
+	rec := &recorder{}
+	f := NewFakeRunner(rec)
+
+	if _, err := git.Run(git.WithRunner(context.Background(), f), "", "push"); err == nil {
+		t.Error("expected error for unscripted invocation")
+	}
+
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "git push") {
+		t.Errorf("errors = %v, want one unexpected git push", rec.errors)
+	}
+}
@@ -0,0 +1,240 @@
+package git
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderRegexp = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// FilePatch is a single-file unified diff split into its file header
+// (diff --git, index, ---/+++ lines) and its hunks.
+type FilePatch struct {
+	Header []string
+	Hunks  []DiffHunk
+}
+
+// ParseFilePatch parses the first file of a unified diff. Hunk IDs are
+// derived from the path and the hunk body rather than line numbers, so a
+// hunk keeps its ID when other hunks in the same file are staged.
+func ParseFilePatch(path, patch string) FilePatch {
+	var fp FilePatch
+	var current *DiffHunk
+
+	seen := make(map[string]int)
+	finish := func() {
+		if current == nil {
+			return
+		}
+
+		current.ID = uniqueHunkID(seen, path, current.Lines)
+
+		fp.Hunks = append(fp.Hunks, *current)
+		current = nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if matches := hunkHeaderRegexp.FindStringSubmatch(line); matches != nil {
+			finish()
+			current = &DiffHunk{
+				Header:   line,
+				OldStart: atoiDefault(matches[1], 0),
+				OldLines: atoiDefault(matches[2], 1),
+				NewStart: atoiDefault(matches[3], 0),
+				NewLines: atoiDefault(matches[4], 1),
+			}
+			continue
+		}
+
+		if current == nil {
+			if line != "" {
+				fp.Header = append(fp.Header, line)
+			}
+			continue
+		}
+
+		// A second file starts; only the first one is parsed.
+		if strings.HasPrefix(line, "diff --git ") {
+			break
+		}
+
+		if line != "" && strings.ContainsRune(" +-\\", rune(line[0])) {
+			current.Lines = append(current.Lines, line)
+		}
+	}
+
+	finish()
+
+	if fp.Hunks == nil {
+		fp.Hunks = []DiffHunk{}
+	}
+
+	return fp
+}
+
+// BuildHunkPatch builds a patch from the hunks of fp whose IDs are in
+// selected. A nil position list keeps the whole hunk; otherwise only the
+// changed lines at those 1-based positions within the hunk's Lines are kept.
+// When reverse is true the patch is meant for `git apply --reverse`, so
+// unselected lines are rewritten relative to the new side instead of the old.
+// Returns an empty string when nothing is selected.
+func BuildHunkPatch(fp FilePatch, selected map[string][]int, reverse bool) (string, error) {
+	var body []string
+	delta := 0
+
+	for _, hunk := range fp.Hunks {
+		positions, ok := selected[hunk.ID]
+		if !ok {
+			continue
+		}
+
+		lines, oldCount, newCount, err := selectHunkLines(hunk.Lines, positions, reverse)
+		if err != nil {
+			return "", fmt.Errorf("hunk %s: %w", hunk.ID, err)
+		}
+
+		if lines == nil {
+			continue
+		}
+
+		oldStart, newStart := hunk.OldStart, hunk.NewStart
+		if reverse {
+			oldStart = shiftedStart(newStart, newCount, oldCount, delta)
+			delta += oldCount - newCount
+		} else {
+			newStart = shiftedStart(oldStart, oldCount, newCount, delta)
+			delta += newCount - oldCount
+		}
+
+		section := ""
+		if matches := hunkHeaderRegexp.FindStringSubmatch(hunk.Header); matches != nil {
+			section = matches[5]
+		}
+
+		body = append(body, fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", oldStart, oldCount, newStart, newCount, section))
+		body = append(body, lines...)
+	}
+
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	return strings.Join(append(append([]string{}, fp.Header...), body...), "\n") + "\n", nil
+}
+
+// selectHunkLines filters a hunk body down to the selected changes. Lines
+// that are not selected must keep the preimage unchanged: on a forward patch
+// an unselected addition is dropped and an unselected deletion becomes
+// context; on a reverse patch the roles swap. Returns nil if no change
+// remains.
+func selectHunkLines(lines []string, positions []int, reverse bool) ([]string, int, int, error) {
+	keep := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		keep[p] = true
+	}
+
+	dropKind := byte('+')
+	if reverse {
+		dropKind = '-'
+	}
+
+	var out []string
+	oldCount, newCount := 0, 0
+	changed := false
+	lastDropped := false
+
+	for i, line := range lines {
+		kind := line[0]
+
+		if kind == '\\' {
+			if !lastDropped {
+				out = append(out, line)
+			}
+			continue
+		}
+
+		// A context line without a trailing newline ends the file on both
+		// sides, so nothing may follow it.
+		if n := len(out); n >= 2 && out[n-1][0] == '\\' && out[n-2][0] == ' ' {
+			return nil, 0, 0, fmt.Errorf("cannot split changes around a line without a trailing newline; select the whole hunk")
+		}
+
+		lastDropped = false
+
+		if kind != ' ' && positions != nil && !keep[i+1] {
+			if kind == dropKind {
+				lastDropped = true
+				continue
+			}
+			line = " " + line[1:]
+			kind = ' '
+		}
+
+		switch kind {
+		case ' ':
+			oldCount++
+			newCount++
+		case '-':
+			oldCount++
+			changed = true
+		case '+':
+			newCount++
+			changed = true
+		}
+
+		out = append(out, line)
+	}
+
+	if !changed {
+		return nil, 0, 0, nil
+	}
+
+	return out, oldCount, newCount, nil
+}
+
+// shiftedStart computes the start line of the side being written from the
+// start of the side git apply will match against, accounting for the changes
+// of previously selected hunks and the zero-length range convention (a
+// range with no lines names the line before it).
+func shiftedStart(preStart, preCount, postCount, delta int) int {
+	start := preStart + delta
+	if preCount == 0 && postCount > 0 {
+		start++
+	} else if preCount > 0 && postCount == 0 {
+		start--
+	}
+	return start
+}
+
+func hunkID(path string, lines []string) string {
+	sum := sha1.Sum([]byte(path + "\x00" + strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])[:10]
+}
+
+// uniqueHunkID returns the hunk ID for lines, suffixed with "-N" when an
+// identical hunk body already appeared earlier in the same file.
+func uniqueHunkID(seen map[string]int, path string, lines []string) string {
+	id := hunkID(path, lines)
+	seen[id]++
+	if n := seen[id]; n > 1 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return id
+}
+
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
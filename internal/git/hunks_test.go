@@ -0,0 +1,161 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+const twoHunkPatch = `diff --git a/f b/f
+index 4f7cbe7..016fa61 100644
+--- a/f
++++ b/f
+@@ -1,4 +1,4 @@
+-a
++A
+ b
+ c
+ d
+@@ -11,4 +11,5 @@ j
+ k
+ l
+ m
+-n
++N
++O
+`
+
+func TestParseFilePatch(t *testing.T) {
+	fp := ParseFilePatch("f", twoHunkPatch)
+
+	if len(fp.Header) != 4 {
+		t.Fatalf("header count = %d, want 4", len(fp.Header))
+	}
+
+	if len(fp.Hunks) != 2 {
+		t.Fatalf("hunks count = %d, want 2", len(fp.Hunks))
+	}
+
+	h := fp.Hunks[1]
+	if h.OldStart != 11 || h.OldLines != 4 || h.NewStart != 11 || h.NewLines != 5 {
+		t.Errorf("hunk 1 range = -%d,%d +%d,%d, want -11,4 +11,5", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+	}
+
+	if len(h.Lines) != 6 {
+		t.Errorf("hunk 1 lines = %d, want 6", len(h.Lines))
+	}
+
+	if fp.Hunks[0].ID == "" || fp.Hunks[0].ID == fp.Hunks[1].ID {
+		t.Errorf("hunk ids = %q, %q, want distinct non-empty", fp.Hunks[0].ID, fp.Hunks[1].ID)
+	}
+}
+
+func TestParseFilePatchIDStableAcrossLineShifts(t *testing.T) {
+	shifted := strings.Replace(twoHunkPatch, "@@ -11,4 +11,5 @@", "@@ -12,4 +12,5 @@", 1)
+
+	a := ParseFilePatch("f", twoHunkPatch)
+	b := ParseFilePatch("f", shifted)
+
+	if a.Hunks[1].ID != b.Hunks[1].ID {
+		t.Errorf("hunk id changed with line numbers: %q vs %q", a.Hunks[1].ID, b.Hunks[1].ID)
+	}
+}
+
+func TestParseFilePatchEmpty(t *testing.T) {
+	fp := ParseFilePatch("f", "")
+
+	if len(fp.Hunks) != 0 {
+		t.Errorf("hunks count = %d, want 0", len(fp.Hunks))
+	}
+}
+
+func TestBuildHunkPatchWholeHunk(t *testing.T) {
+	fp := ParseFilePatch("f", twoHunkPatch)
+
+	patch, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[1].ID: nil}, false)
+	if err != nil {
+		t.Fatalf("BuildHunkPatch: %v", err)
+	}
+
+	if strings.Contains(patch, "+A") {
+		t.Errorf("patch contains unselected hunk:\n%s", patch)
+	}
+
+	if !strings.Contains(patch, "@@ -11,4 +11,5 @@ j\n") {
+		t.Errorf("patch missing header for selected hunk:\n%s", patch)
+	}
+
+	if !strings.HasPrefix(patch, "diff --git a/f b/f\n") {
+		t.Errorf("patch missing file header:\n%s", patch)
+	}
+}
+
+func TestBuildHunkPatchSelectedLines(t *testing.T) {
+	fp := ParseFilePatch("f", twoHunkPatch)
+
+	// Keep only "+O": "-n" becomes context and "+N" is dropped.
+	patch, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[1].ID: {6}}, false)
+	if err != nil {
+		t.Fatalf("BuildHunkPatch: %v", err)
+	}
+
+	want := "@@ -11,4 +11,5 @@ j\n k\n l\n m\n n\n+O\n"
+	if !strings.HasSuffix(patch, want) {
+		t.Errorf("patch =\n%s\nwant suffix\n%s", patch, want)
+	}
+}
+
+func TestBuildHunkPatchShiftsLaterHunks(t *testing.T) {
+	patch := strings.Replace(twoHunkPatch, "-a\n+A\n", "+A\n", 1)
+	patch = strings.Replace(patch, "@@ -1,4 +1,4 @@", "@@ -1,3 +1,4 @@", 1)
+	fp := ParseFilePatch("f", patch)
+
+	out, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[0].ID: nil, fp.Hunks[1].ID: nil}, false)
+	if err != nil {
+		t.Fatalf("BuildHunkPatch: %v", err)
+	}
+
+	if !strings.Contains(out, "@@ -11,4 +12,5 @@ j\n") {
+		t.Errorf("second hunk not shifted by first:\n%s", out)
+	}
+}
+
+func TestBuildHunkPatchReverse(t *testing.T) {
+	fp := ParseFilePatch("f", twoHunkPatch)
+
+	// Unstage only "-n": "+N" becomes context and "+O" stays in the index.
+	patch, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[1].ID: {4}}, true)
+	if err != nil {
+		t.Fatalf("BuildHunkPatch: %v", err)
+	}
+
+	want := "@@ -11,6 +11,5 @@ j\n k\n l\n m\n-n\n N\n O\n"
+	if !strings.HasSuffix(patch, want) {
+		t.Errorf("patch =\n%s\nwant suffix\n%s", patch, want)
+	}
+}
+
+func TestBuildHunkPatchNothingSelected(t *testing.T) {
+	fp := ParseFilePatch("f", twoHunkPatch)
+
+	patch, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[1].ID: {1, 2}}, false)
+	if err != nil {
+		t.Fatalf("BuildHunkPatch: %v", err)
+	}
+
+	if patch != "" {
+		t.Errorf("patch = %q, want empty when only context lines are selected", patch)
+	}
+}
+
+func TestBuildHunkPatchNoNewlineSplit(t *testing.T) {
+	input := "diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1 +1 @@\n-x\n\\ No newline at end of file\n+y\n\\ No newline at end of file\n"
+	fp := ParseFilePatch("f", input)
+
+	if _, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[0].ID: {3}}, false); err == nil {
+		t.Error("expected error splitting around a line without trailing newline")
+	}
+
+	if _, err := BuildHunkPatch(fp, map[string][]int{fp.Hunks[0].ID: nil}, false); err != nil {
+		t.Errorf("whole hunk: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// staleLockAge is how old a lock file nobody has open must be before it is
+// reported as stale. Git briefly closes a lock before renaming it into
+// place, so a young lock without a holder is usually about to disappear.
+const staleLockAge = time.Minute
+
+// maxLockRetryDelay caps the exponential backoff between retries.
+const maxLockRetryDelay = 2 * time.Second
+
+// LockInfo describes a lock file that made git fail.
+type LockInfo struct {
+	Path       string  `json:"path"`
+	AgeSeconds float64 `json:"age_seconds"`
+	PID        int     `json:"pid,omitempty"`
+	Command    string  `json:"command,omitempty"`
+	Stale      bool    `json:"stale"`
+}
+
+func (l *LockInfo) String() string {
+	switch {
+	case l.PID != 0:
+		return fmt.Sprintf("%s is held by pid %d (%s)", l.Path, l.PID, l.Command)
+	case l.Stale:
+		return fmt.Sprintf("%s looks stale: no process has it open and it is %s old; remove it if no git process is running", l.Path, time.Duration(l.AgeSeconds*float64(time.Second)).Round(time.Second))
+	default:
+		return fmt.Sprintf("%s exists", l.Path)
+	}
+}
+
+var lockPathPattern = regexp.MustCompile(`Unable to create '([^']+\.lock)': File exists`)
+
+// InspectLock finds the lock file named in git's stderr and reports its
+// age and, where /proc is available, the process holding it open. It
+// returns nil if stderr names no lock or the lock is already gone.
+func InspectLock(stderr string) *LockInfo {
+	m := lockPathPattern.FindStringSubmatch(stderr)
+	if m == nil {
+		return nil
+	}
+
+	info, err := os.Stat(m[1])
+	if err != nil {
+		return nil
+	}
+
+	lock := &LockInfo{
+		Path:       m[1],
+		AgeSeconds: time.Since(info.ModTime()).Seconds(),
+	}
+
+	pid, command, scanned := lockHolder(m[1])
+	lock.PID = pid
+	lock.Command = command
+	lock.Stale = scanned && pid == 0 && time.Since(info.ModTime()) >= staleLockAge
+
+	return lock
+}
+
+// lockHolder scans /proc for a process with path open. scanned is false
+// when /proc is unavailable, in which case nothing can be said about
+// whether the lock is held.
+func lockHolder(path string) (pid int, command string, scanned bool) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, "", false
+	}
+
+	for _, proc := range procs {
+		id, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err == nil && target == path {
+				return id, processCommand(proc.Name()), true
+			}
+		}
+	}
+
+	return 0, "", true
+}
+
+func processCommand(pid string) string {
+	data, err := os.ReadFile(filepath.Join("/proc", pid, "cmdline"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+
+// LockRetryRunner retries invocations that fail because another git
+// process holds a lock file, waiting Delay before the first retry and
+// doubling it each time. When the retries run out, the returned *Error
+// describes the lock and whoever holds it.
+type LockRetryRunner struct {
+	Runner  Runner
+	Retries int
+	Delay   time.Duration
+}
+
+// Run implements Runner.
+func (r LockRetryRunner) Run(ctx context.Context, inv Invocation) (string, error) {
+	delay := r.Delay
+
+	for attempt := 0; ; attempt++ {
+		out, err := r.Runner.Run(ctx, inv)
+		if KindOf(err) != KindLockFileExists {
+			return out, err
+		}
+
+		if attempt >= r.Retries {
+			var gitErr *Error
+			if errors.As(err, &gitErr) {
+				gitErr.Lock = InspectLock(gitErr.Stderr)
+			}
+			return out, err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return out, err
+		}
+
+		delay = min(delay*2, maxLockRetryDelay)
+	}
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedRunner fails with the queued errors in order, then succeeds.
+type scriptedRunner struct {
+	errs  []error
+	calls int
+}
+
+func (r *scriptedRunner) Run(ctx context.Context, inv Invocation) (string, error) {
+	r.calls++
+	if len(r.errs) == 0 {
+		return "ok", nil
+	}
+	err := r.errs[0]
+	r.errs = r.errs[1:]
+	return "", err
+}
+
+func lockError(path string) *Error {
+	stderr := "fatal: Unable to create '" + path + "': File exists.\n\nAnother git process seems to be running in this repository\n"
+	return &Error{Args: []string{"add", "."}, ExitCode: 128, Stderr: stderr, Kind: ClassifyError(stderr, ""), Err: errors.New("exit status 128")}
+}
+
+func TestLockRetryRunnerRetries(t *testing.T) {
+	inner := &scriptedRunner{errs: []error{lockError("/repo/.git/index.lock"), lockError("/repo/.git/index.lock")}}
+	r := LockRetryRunner{Runner: inner, Retries: 3, Delay: time.Millisecond}
+
+	out, err := r.Run(t.Context(), Invocation{Args: []string{"add", "."}})
+	if err != nil || out != "ok" {
+		t.Fatalf("Run = %q, %v, want ok", out, err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("calls = %d, want 3", inner.calls)
+	}
+}
+
+func TestLockRetryRunnerGivesUp(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "index.lock")
+	if err := os.WriteFile(lockPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := &scriptedRunner{errs: []error{lockError(lockPath), lockError(lockPath), lockError(lockPath)}}
+	r := LockRetryRunner{Runner: inner, Retries: 2, Delay: time.Millisecond}
+
+	_, err := r.Run(t.Context(), Invocation{Args: []string{"add", "."}})
+
+	var gitErr *Error
+	if !errors.As(err, &gitErr) || gitErr.Kind != KindLockFileExists {
+		t.Fatalf("err = %v, want lock_file_exists", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("calls = %d, want 3", inner.calls)
+	}
+
+	if gitErr.Lock == nil || gitErr.Lock.Path != lockPath {
+		t.Fatalf("Lock = %+v, want path %s", gitErr.Lock, lockPath)
+	}
+	if _, err := os.Stat("/proc/self/fd"); err == nil && !gitErr.Lock.Stale {
+		t.Errorf("Lock = %+v, want stale", gitErr.Lock)
+	}
+	if !strings.Contains(err.Error(), lockPath) {
+		t.Errorf("Error() = %q, want lock path", err.Error())
+	}
+}
+
+func TestLockRetryRunnerPassesOtherErrors(t *testing.T) {
+	other := &Error{Kind: KindMergeConflict, Err: errors.New("exit status 1")}
+	inner := &scriptedRunner{errs: []error{other}}
+	r := LockRetryRunner{Runner: inner, Retries: 3, Delay: time.Millisecond}
+
+	if _, err := r.Run(t.Context(), Invocation{}); err != other {
+		t.Errorf("err = %v, want %v", err, other)
+	}
+	if inner.calls != 1 {
+		t.Errorf("calls = %d, want 1", inner.calls)
+	}
+}
+
+func TestInspectLockFindsHolder(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("/proc not available")
+	}
+
+	lockPath := filepath.Join(t.TempDir(), "index.lock")
+	f, err := os.Create(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	lock := InspectLock(lockError(lockPath).Stderr)
+	if lock == nil {
+		t.Fatal("InspectLock = nil")
+	}
+	if lock.PID != os.Getpid() || lock.Stale {
+		t.Errorf("lock = %+v, want held by pid %d", lock, os.Getpid())
+	}
+}
+
+func TestInspectLockGone(t *testing.T) {
+	if lock := InspectLock(lockError("/nonexistent/index.lock").Stderr); lock != nil {
+		t.Errorf("InspectLock = %+v, want nil", lock)
+	}
+	if lock := InspectLock("fatal: something else\n"); lock != nil {
+		t.Errorf("InspectLock = %+v, want nil", lock)
+	}
+}
+
+func TestResolveGitDir(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	worktreeGitDir := filepath.Join(repo, ".git", "worktrees", "wt")
+	if err := os.MkdirAll(worktreeGitDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	worktree := filepath.Join(root, "wt")
+	if err := os.Mkdir(worktree, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../repo/.git/worktrees/wt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{repo, filepath.Join(repo, ".git")},
+		{sub, filepath.Join(repo, ".git")},
+		{worktree, worktreeGitDir},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveGitDir(tt.dir)
+		if err != nil {
+			t.Errorf("ResolveGitDir(%s): %v", tt.dir, err)
+			continue
+		}
+
+		want, _ := filepath.EvalSymlinks(tt.want)
+		if got != want {
+			t.Errorf("ResolveGitDir(%s) = %s, want %s", tt.dir, got, want)
+		}
+	}
+
+	if _, err := ResolveGitDir(root); err == nil {
+		t.Errorf("ResolveGitDir(%s): expected error outside a repository", root)
+	}
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DetectInProgress reports the multi-step operations stopped partway in the
+// git directory gitDir, along with the grit tool that continues or aborts
+// each one. More than one can be in progress, e.g. a merge during a bisect.
+func DetectInProgress(gitDir string) []OperationInProgress {
+	var ops []OperationInProgress
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("rebase-merge"):
+		ops = append(ops, OperationInProgress{Operation: "rebase", Backend: "merge", Tool: "rebase"})
+	case exists(filepath.Join("rebase-apply", "applying")):
+		ops = append(ops, OperationInProgress{Operation: "am"})
+	case exists("rebase-apply"):
+		ops = append(ops, OperationInProgress{Operation: "rebase", Backend: "apply", Tool: "rebase"})
+	}
+
+	if exists("MERGE_HEAD") {
+		ops = append(ops, OperationInProgress{Operation: "merge", Tool: "merge"})
+	}
+
+	// A multi-commit cherry-pick or revert keeps its todo list in
+	// sequencer/ even between stops, when the *_HEAD file is gone.
+	switch {
+	case exists("CHERRY_PICK_HEAD"):
+		ops = append(ops, OperationInProgress{Operation: "cherry_pick", Tool: "cherry_pick"})
+	case exists("REVERT_HEAD"):
+		ops = append(ops, OperationInProgress{Operation: "revert", Tool: "revert"})
+	default:
+		switch sequencerCommand(gitDir) {
+		case "pick":
+			ops = append(ops, OperationInProgress{Operation: "cherry_pick", Tool: "cherry_pick"})
+		case "revert":
+			ops = append(ops, OperationInProgress{Operation: "revert", Tool: "revert"})
+		}
+	}
+
+	if exists("BISECT_LOG") {
+		ops = append(ops, OperationInProgress{Operation: "bisect"})
+	}
+
+	return ops
+}
+
+// sequencerCommand returns the first command in sequencer/todo, or "" if
+// there is no sequencer state.
+func sequencerCommand(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "sequencer", "todo"))
+	if err != nil {
+		return ""
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectInProgress(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []OperationInProgress
+	}{
+		{"clean", nil, nil},
+		{
+			"merge",
+			map[string]string{"MERGE_HEAD": "abc\n"},
+			[]OperationInProgress{{Operation: "merge", Tool: "merge"}},
+		},
+		{
+			"rebase merge backend",
+			map[string]string{"rebase-merge/head-name": "refs/heads/feature\n"},
+			[]OperationInProgress{{Operation: "rebase", Backend: "merge", Tool: "rebase"}},
+		},
+		{
+			"rebase apply backend",
+			map[string]string{"rebase-apply/rebasing": ""},
+			[]OperationInProgress{{Operation: "rebase", Backend: "apply", Tool: "rebase"}},
+		},
+		{
+			"am",
+			map[string]string{"rebase-apply/applying": ""},
+			[]OperationInProgress{{Operation: "am"}},
+		},
+		{
+			"cherry-pick stopped",
+			map[string]string{"CHERRY_PICK_HEAD": "abc\n", "sequencer/todo": "pick def second\n"},
+			[]OperationInProgress{{Operation: "cherry_pick", Tool: "cherry_pick"}},
+		},
+		{
+			"revert between stops",
+			map[string]string{"sequencer/todo": "revert abc first\n"},
+			[]OperationInProgress{{Operation: "revert", Tool: "revert"}},
+		},
+		{
+			"merge during bisect",
+			map[string]string{"MERGE_HEAD": "abc\n", "BISECT_LOG": "git bisect start\n"},
+			[]OperationInProgress{{Operation: "merge", Tool: "merge"}, {Operation: "bisect"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(gitDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := DetectInProgress(gitDir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectInProgress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
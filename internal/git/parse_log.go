@@ -8,33 +8,83 @@ import (
 const logRecordSep = "\x1e"
 const logFieldSep = "\x1f"
 
-const LogFormat = "%H" + logFieldSep + "%an" + logFieldSep + "%ae" + logFieldSep + "%aI" + logFieldSep + "%s" + logFieldSep + "%b" + logRecordSep
+// logCommitFields are the fields every log record starts with. Trailers
+// come out one "Key: value" per line.
+const logCommitFields = "%H" + logFieldSep + "%P" + logFieldSep +
+	"%an" + logFieldSep + "%ae" + logFieldSep + "%aI" + logFieldSep +
+	"%cn" + logFieldSep + "%ce" + logFieldSep + "%cI" + logFieldSep +
+	"%D" + logFieldSep + "%(trailers:only,unfold)" + logFieldSep
+
+// LogFormat starts each record with logRecordSep and ends it with a field
+// separator, so that anything git prints after the formatted commit, such
+// as --numstat lines, lands in the record's final field. The signature
+// fields are left empty; checking them runs gpg or ssh-keygen for every
+// commit.
+const LogFormat = logRecordSep + logCommitFields +
+	logFieldSep + logFieldSep + logFieldSep +
+	"%s" + logFieldSep + "%b" + logFieldSep
+
+// SignedLogFormat is LogFormat with the signature status, signer and key
+// filled in.
+const SignedLogFormat = logRecordSep + logCommitFields +
+	"%G?" + logFieldSep + "%GS" + logFieldSep + "%GK" + logFieldSep +
+	"%s" + logFieldSep + "%b" + logFieldSep
+
+const logFieldCount = 16
+
+// signatureStatuses maps %G? codes to the names reported in
+// CommitSignature.Status.
+var signatureStatuses = map[string]string{
+	"G": "good",
+	"B": "bad",
+	"U": "good_unknown_validity",
+	"X": "good_expired",
+	"Y": "good_expired_key",
+	"R": "good_revoked_key",
+	"E": "cannot_check",
+	"N": "none",
+}
 
+// ParseLog parses git log output produced with LogFormat or
+// SignedLogFormat, optionally with --numstat. Decorations are classified
+// by their full ref names, so callers wanting branches and tags told apart
+// should pass --decorate=full.
 func ParseLog(output string) []LogEntry {
 	var entries []LogEntry
 
 	records := strings.Split(output, logRecordSep)
 	for _, record := range records {
-		record = strings.TrimSpace(record)
-		if record == "" {
+		if strings.TrimSpace(record) == "" {
 			continue
 		}
 
-		fields := strings.SplitN(record, logFieldSep, 6)
-		if len(fields) < 5 {
+		fields := strings.SplitN(record, logFieldSep, logFieldCount)
+		if len(fields) < logFieldCount-1 {
 			continue
 		}
 
 		entry := LogEntry{
-			Hash:        strings.TrimSpace(fields[0]),
-			AuthorName:  strings.TrimSpace(fields[1]),
-			AuthorEmail: strings.TrimSpace(fields[2]),
-			AuthorDate:  strings.TrimSpace(fields[3]),
-			Subject:     strings.TrimSpace(fields[4]),
+			Hash:           strings.TrimSpace(fields[0]),
+			Parents:        strings.Fields(fields[1]),
+			AuthorName:     strings.TrimSpace(fields[2]),
+			AuthorEmail:    strings.TrimSpace(fields[3]),
+			AuthorDate:     strings.TrimSpace(fields[4]),
+			CommitterName:  strings.TrimSpace(fields[5]),
+			CommitterEmail: strings.TrimSpace(fields[6]),
+			CommitterDate:  strings.TrimSpace(fields[7]),
+			Refs:           parseDecorations(fields[8]),
+			Trailers:       parseTrailers(fields[9]),
+			Signature:      parseSignature(fields[10], fields[11], fields[12]),
+			Subject:        strings.TrimSpace(fields[13]),
+			Body:           strings.TrimSpace(fields[14]),
 		}
 
-		if len(fields) > 5 {
-			entry.Body = strings.TrimSpace(fields[5])
+		if entry.Parents == nil {
+			entry.Parents = []string{}
+		}
+
+		if len(fields) > 15 && strings.TrimSpace(fields[15]) != "" {
+			entry.Stats = ParseDiffNumstat(fields[15])
 		}
 
 		entries = append(entries, entry)
@@ -47,6 +97,83 @@ func ParseLog(output string) []LogEntry {
 	return entries
 }
 
+// parseDecorations splits %D output such as
+// "HEAD -> refs/heads/main, tag: refs/tags/v1.0, refs/remotes/origin/main".
+// Names without a refs/ prefix, as printed under --decorate=short, are
+// reported as branches.
+func parseDecorations(field string) *CommitRefs {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return nil
+	}
+
+	refs := &CommitRefs{}
+
+	for _, name := range strings.Split(field, ", ") {
+		if rest, ok := strings.CutPrefix(name, "HEAD -> "); ok {
+			refs.Head = true
+			name = rest
+		} else if name == "HEAD" {
+			refs.Head = true
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(name, "tag: "); ok {
+			refs.Tags = append(refs.Tags, strings.TrimPrefix(rest, "refs/tags/"))
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(name, "refs/remotes/"):
+			refs.RemoteBranches = append(refs.RemoteBranches, strings.TrimPrefix(name, "refs/remotes/"))
+		case strings.HasPrefix(name, "refs/heads/"):
+			refs.Branches = append(refs.Branches, strings.TrimPrefix(name, "refs/heads/"))
+		case strings.HasPrefix(name, "refs/"):
+			refs.Other = append(refs.Other, name)
+		default:
+			refs.Branches = append(refs.Branches, name)
+		}
+	}
+
+	return refs
+}
+
+func parseTrailers(field string) []Trailer {
+	var trailers []Trailer
+
+	for _, line := range strings.Split(field, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		trailers = append(trailers, Trailer{
+			Key:   strings.TrimSpace(key),
+			Value: strings.TrimSpace(value),
+		})
+	}
+
+	return trailers
+}
+
+func parseSignature(code, signer, key string) *CommitSignature {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil
+	}
+
+	status, ok := signatureStatuses[code]
+	if !ok {
+		status = code
+	}
+
+	return &CommitSignature{
+		Status: status,
+		Signer: strings.TrimSpace(signer),
+		Key:    strings.TrimSpace(key),
+	}
+}
+
 const ShowFormat = "%H" + logFieldSep + "%an" + logFieldSep + "%ae" + logFieldSep + "%aI" + logFieldSep + "%s" + logFieldSep + "%b" + logRecordSep
 
 func ParseShow(metadataOutput, numstatOutput, patchOutput string) ShowResult {
@@ -1,11 +1,13 @@
 package git
 
 import (
+	"reflect"
 	"testing"
 )
 
 func TestParseLog(t *testing.T) {
-	input := "abc123def456\x1fJohn Doe\x1fjohn@example.com\x1f2024-01-15T10:30:00-05:00\x1fInitial commit\x1fThis is the body\x1edef789abc123\x1fJane Smith\x1fjane@example.com\x1f2024-01-14T09:00:00-05:00\x1fAdd feature\x1f\x1e"
+	input := "\x1eabc123def456\x1fdef789abc123\x1fJohn Doe\x1fjohn@example.com\x1f2024-01-15T10:30:00-05:00\x1fJane Smith\x1fjane@example.com\x1f2024-01-16T08:00:00-05:00\x1f\x1f\x1f\x1f\x1f\x1fInitial commit\x1fThis is the body\x1f\n" +
+		"\x1edef789abc123\x1f\x1fJane Smith\x1fjane@example.com\x1f2024-01-14T09:00:00-05:00\x1fJane Smith\x1fjane@example.com\x1f2024-01-14T09:00:00-05:00\x1f\x1f\x1f\x1f\x1f\x1fAdd feature\x1f\x1f\n"
 
 	entries := ParseLog(input)
 
@@ -44,6 +46,70 @@ func TestParseLog(t *testing.T) {
 	if entries[1].Body != "" {
 		t.Errorf("entry 1 body = %q, want empty", entries[1].Body)
 	}
+
+	if entries[0].CommitterName != "Jane Smith" || entries[0].CommitterDate != "2024-01-16T08:00:00-05:00" {
+		t.Errorf("entry 0 committer = %q %q, want Jane Smith", entries[0].CommitterName, entries[0].CommitterDate)
+	}
+
+	if len(entries[0].Parents) != 1 || entries[0].Parents[0] != "def789abc123" {
+		t.Errorf("entry 0 parents = %v, want [def789abc123]", entries[0].Parents)
+	}
+
+	if entries[1].Parents == nil || len(entries[1].Parents) != 0 {
+		t.Errorf("entry 1 parents = %#v, want empty", entries[1].Parents)
+	}
+
+	if entries[0].Refs != nil || entries[0].Trailers != nil || entries[0].Signature != nil || entries[0].Stats != nil {
+		t.Errorf("entry 0 = %+v, want no refs, trailers, signature or stats", entries[0])
+	}
+}
+
+func TestParseLogDetails(t *testing.T) {
+	input := "\x1eabc123\x1fp1 p2\x1fJohn Doe\x1fjohn@example.com\x1f2024-01-15T10:30:00-05:00\x1fJohn Doe\x1fjohn@example.com\x1f2024-01-15T10:30:00-05:00\x1f" +
+		"HEAD -> refs/heads/main, tag: refs/tags/v1.0, refs/remotes/origin/main, refs/notes/x\x1f" +
+		"Signed-off-by: John Doe <john@example.com>\nFixes: #12\n\x1f" +
+		"G\x1fJohn Doe <john@example.com>\x1fABCDEF\x1f" +
+		"Merge feature\x1fBody\n\nSigned-off-by: John Doe <john@example.com>\nFixes: #12\x1f\n" +
+		"\n5\t2\tfile.go\n-\t-\timage.png\n"
+
+	entries := ParseLog(input)
+	if len(entries) != 1 {
+		t.Fatalf("entries count = %d, want 1", len(entries))
+	}
+	entry := entries[0]
+
+	if len(entry.Parents) != 2 {
+		t.Errorf("parents = %v, want 2", entry.Parents)
+	}
+
+	wantRefs := CommitRefs{Head: true, Branches: []string{"main"}, RemoteBranches: []string{"origin/main"}, Tags: []string{"v1.0"}, Other: []string{"refs/notes/x"}}
+	if entry.Refs == nil || !reflect.DeepEqual(*entry.Refs, wantRefs) {
+		t.Errorf("refs = %+v, want %+v", entry.Refs, wantRefs)
+	}
+
+	wantTrailers := []Trailer{{"Signed-off-by", "John Doe <john@example.com>"}, {"Fixes", "#12"}}
+	if !reflect.DeepEqual(entry.Trailers, wantTrailers) {
+		t.Errorf("trailers = %+v, want %+v", entry.Trailers, wantTrailers)
+	}
+
+	wantSig := CommitSignature{Status: "good", Signer: "John Doe <john@example.com>", Key: "ABCDEF"}
+	if entry.Signature == nil || *entry.Signature != wantSig {
+		t.Errorf("signature = %+v, want %+v", entry.Signature, wantSig)
+	}
+
+	wantStats := []DiffStat{{Additions: 5, Deletions: 2, Path: "file.go"}, {Path: "image.png", Binary: true}}
+	if !reflect.DeepEqual(entry.Stats, wantStats) {
+		t.Errorf("stats = %+v, want %+v", entry.Stats, wantStats)
+	}
+}
+
+func TestParseDecorationsShort(t *testing.T) {
+	refs := parseDecorations("HEAD, tag: v1.0, main")
+
+	want := CommitRefs{Head: true, Branches: []string{"main"}, Tags: []string{"v1.0"}}
+	if refs == nil || !reflect.DeepEqual(*refs, want) {
+		t.Errorf("refs = %+v, want %+v", refs, want)
+	}
 }
 
 func TestParseLogEmpty(t *testing.T) {
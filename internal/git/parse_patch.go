@@ -0,0 +1,228 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParsePatch parses the output of `git diff` into files, hunks, and lines.
+// Hunk IDs are computed the same way as ParseFilePatch, so they can be passed
+// straight to stage_hunks.
+func ParsePatch(patch string) []PatchFile {
+	files := []PatchFile{}
+
+	var file *PatchFile
+	var hunk *PatchHunk
+	var rawLines []string
+	var seen map[string]int
+	oldLine, newLine := 0, 0
+
+	finishHunk := func() {
+		if hunk == nil {
+			return
+		}
+
+		hunk.ID = uniqueHunkID(seen, file.Path, rawLines)
+		file.Hunks = append(file.Hunks, *hunk)
+		hunk = nil
+		rawLines = nil
+	}
+
+	finishFile := func() {
+		finishHunk()
+		if file == nil {
+			return
+		}
+
+		if file.Hunks == nil {
+			file.Hunks = []PatchHunk{}
+		}
+
+		files = append(files, *file)
+		file = nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			finishFile()
+			oldPath, newPath := splitDiffGitPaths(strings.TrimPrefix(line, "diff --git "))
+			file = &PatchFile{Path: newPath, OldPath: oldPath, Status: "modified"}
+			seen = make(map[string]int)
+			continue
+		}
+
+		if file == nil {
+			continue
+		}
+
+		if hunk != nil {
+			switch {
+			case line == "":
+				// Some tools strip the trailing space of empty context lines;
+				// ParseFilePatch skips them, so they do not feed the hunk ID.
+				oldLine++
+				newLine++
+				hunk.Lines = append(hunk.Lines, PatchLine{Kind: "context", OldLine: oldLine, NewLine: newLine})
+				continue
+			case line[0] == ' ':
+				oldLine++
+				newLine++
+				hunk.Lines = append(hunk.Lines, PatchLine{Kind: "context", OldLine: oldLine, NewLine: newLine, Content: line[1:]})
+				rawLines = append(rawLines, line)
+				continue
+			case line[0] == '-':
+				oldLine++
+				hunk.Lines = append(hunk.Lines, PatchLine{Kind: "deleted", OldLine: oldLine, Content: line[1:]})
+				rawLines = append(rawLines, line)
+				continue
+			case line[0] == '+':
+				newLine++
+				hunk.Lines = append(hunk.Lines, PatchLine{Kind: "added", NewLine: newLine, Content: line[1:]})
+				rawLines = append(rawLines, line)
+				continue
+			case line[0] == '\\':
+				if n := len(hunk.Lines); n > 0 {
+					hunk.Lines[n-1].NoNewline = true
+				}
+				rawLines = append(rawLines, line)
+				continue
+			}
+		}
+
+		if matches := hunkHeaderRegexp.FindStringSubmatch(line); matches != nil {
+			finishHunk()
+			hunk = &PatchHunk{
+				Header:   line,
+				OldStart: atoiDefault(matches[1], 0),
+				OldLines: atoiDefault(matches[2], 1),
+				NewStart: atoiDefault(matches[3], 0),
+				NewLines: atoiDefault(matches[4], 1),
+				Section:  strings.TrimSpace(matches[5]),
+				Lines:    []PatchLine{},
+			}
+			oldLine, newLine = hunk.OldStart-1, hunk.NewStart-1
+			continue
+		}
+
+		parsePatchHeader(file, line)
+	}
+
+	finishFile()
+
+	for i := range files {
+		if files[i].OldPath == files[i].Path {
+			files[i].OldPath = ""
+		}
+	}
+
+	return files
+}
+
+// parsePatchHeader applies one extended header line of `git diff` to file.
+func parsePatchHeader(file *PatchFile, line string) {
+	switch {
+	case strings.HasPrefix(line, "new file mode "):
+		file.Status = "added"
+		file.NewMode = strings.TrimPrefix(line, "new file mode ")
+	case strings.HasPrefix(line, "deleted file mode "):
+		file.Status = "deleted"
+		file.OldMode = strings.TrimPrefix(line, "deleted file mode ")
+	case strings.HasPrefix(line, "old mode "):
+		file.OldMode = strings.TrimPrefix(line, "old mode ")
+	case strings.HasPrefix(line, "new mode "):
+		file.NewMode = strings.TrimPrefix(line, "new mode ")
+	case strings.HasPrefix(line, "similarity index "):
+		file.Similarity = atoiDefault(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"), 0)
+	case strings.HasPrefix(line, "rename from "):
+		file.Status = "renamed"
+		file.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+	case strings.HasPrefix(line, "rename to "):
+		file.Status = "renamed"
+		file.Path = unquotePath(strings.TrimPrefix(line, "rename to "))
+	case strings.HasPrefix(line, "copy from "):
+		file.Status = "copied"
+		file.OldPath = unquotePath(strings.TrimPrefix(line, "copy from "))
+	case strings.HasPrefix(line, "copy to "):
+		file.Status = "copied"
+		file.Path = unquotePath(strings.TrimPrefix(line, "copy to "))
+	case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+		file.Binary = true
+	case strings.HasPrefix(line, "--- "):
+		if path := stripPatchPrefix(strings.TrimPrefix(line, "--- ")); path != "" {
+			file.OldPath = path
+		}
+	case strings.HasPrefix(line, "+++ "):
+		if path := stripPatchPrefix(strings.TrimPrefix(line, "+++ ")); path != "" {
+			file.Path = path
+		}
+	}
+}
+
+// splitDiffGitPaths splits the "a/<old> b/<new>" part of a diff --git line.
+// Unquoted paths may contain spaces, so when both sides are the same path
+// the line is split in the middle rather than at the first " b/".
+func splitDiffGitPaths(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		if end := quotedEnd(s); end > 0 {
+			return stripPatchPrefix(s[:end]), stripPatchPrefix(strings.TrimSpace(s[end:]))
+		}
+	}
+
+	if len(s)%2 == 1 {
+		mid := len(s) / 2
+		oldPath, newPath := stripPatchPrefix(s[:mid]), stripPatchPrefix(s[mid+1:])
+		if oldPath == newPath {
+			return oldPath, newPath
+		}
+	}
+
+	if i := strings.Index(s, " b/"); i >= 0 {
+		return stripPatchPrefix(s[:i]), stripPatchPrefix(s[i+1:])
+	}
+
+	return stripPatchPrefix(s), stripPatchPrefix(s)
+}
+
+// stripPatchPrefix unquotes a path from a diff header and removes its a/ or
+// b/ prefix. Returns an empty string for /dev/null.
+func stripPatchPrefix(s string) string {
+	s = unquotePath(strings.TrimRight(s, "\t"))
+	if s == "/dev/null" {
+		return ""
+	}
+
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+
+	return s
+}
+
+// unquotePath decodes a path git quoted because it contains special
+// characters (core.quotePath).
+func unquotePath(s string) string {
+	if !strings.HasPrefix(s, `"`) {
+		return s
+	}
+
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return s
+	}
+
+	return unquoted
+}
+
+// quotedEnd returns the index just past the closing quote of the quoted
+// string at the start of s, or -1 if it is unterminated.
+func quotedEnd(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,161 @@
+package git
+
+import "testing"
+
+const mixedPatch = `diff --git a/bin b/bin
+new file mode 100644
+index 0000000..bdc955b
+Binary files /dev/null and b/bin differ
+diff --git a/f b/g
+similarity index 93%
+rename from f
+rename to g
+index 4f7cbe7..814fe87 100644
+--- a/f
++++ b/g
+@@ -12,3 +12,4 @@ k
+ l
+ m
+ n
++x
+diff --git a/nonl b/nonl
+old mode 100644
+new mode 100755
+index 1111111..2222222
+--- a/nonl
++++ b/nonl
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+diff --git a/gone b/gone
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+func TestParsePatch(t *testing.T) {
+	files := ParsePatch(mixedPatch)
+
+	if len(files) != 4 {
+		t.Fatalf("files count = %d, want 4", len(files))
+	}
+
+	bin := files[0]
+	if bin.Path != "bin" || bin.Status != "added" || !bin.Binary || bin.NewMode != "100644" {
+		t.Errorf("bin = %+v, want added binary with mode 100644", bin)
+	}
+	if bin.Hunks == nil || len(bin.Hunks) != 0 {
+		t.Errorf("bin hunks = %v, want empty slice", bin.Hunks)
+	}
+
+	renamed := files[1]
+	if renamed.Path != "g" || renamed.OldPath != "f" || renamed.Status != "renamed" || renamed.Similarity != 93 {
+		t.Errorf("renamed = %+v, want f -> g at 93%%", renamed)
+	}
+	if len(renamed.Hunks) != 1 {
+		t.Fatalf("renamed hunks = %d, want 1", len(renamed.Hunks))
+	}
+
+	h := renamed.Hunks[0]
+	if h.Section != "k" {
+		t.Errorf("section = %q, want %q", h.Section, "k")
+	}
+	last := h.Lines[3]
+	if last.Kind != "added" || last.NewLine != 15 || last.OldLine != 0 || last.Content != "x" {
+		t.Errorf("last line = %+v, want added x at new line 15", last)
+	}
+	first := h.Lines[0]
+	if first.Kind != "context" || first.OldLine != 12 || first.NewLine != 12 {
+		t.Errorf("first line = %+v, want context at 12/12", first)
+	}
+
+	mode := files[2]
+	if mode.Status != "modified" || mode.OldMode != "100644" || mode.NewMode != "100755" {
+		t.Errorf("mode = %+v, want modified 100644 -> 100755", mode)
+	}
+	lines := mode.Hunks[0].Lines
+	if len(lines) != 3 {
+		t.Fatalf("mode lines = %d, want 3", len(lines))
+	}
+	if !lines[1].NoNewline || lines[1].Kind != "deleted" || lines[1].OldLine != 2 {
+		t.Errorf("deleted line = %+v, want deleted at old line 2 without newline", lines[1])
+	}
+	if !lines[2].NoNewline || lines[2].Kind != "added" || lines[2].NewLine != 2 {
+		t.Errorf("added line = %+v, want added at new line 2 without newline", lines[2])
+	}
+	if lines[0].NoNewline {
+		t.Errorf("context line marked without newline")
+	}
+
+	gone := files[3]
+	if gone.Path != "gone" || gone.OldPath != "" || gone.Status != "deleted" || gone.OldMode != "100644" {
+		t.Errorf("gone = %+v, want deleted gone", gone)
+	}
+	if l := gone.Hunks[0].Lines[0]; l.Kind != "deleted" || l.OldLine != 1 {
+		t.Errorf("gone line = %+v, want deleted at old line 1", l)
+	}
+}
+
+func TestParsePatchHunkIDsMatchParseFilePatch(t *testing.T) {
+	files := ParsePatch(twoHunkPatch)
+	fp := ParseFilePatch("f", twoHunkPatch)
+
+	if len(files) != 1 || len(files[0].Hunks) != len(fp.Hunks) {
+		t.Fatalf("hunk counts differ: %d files, want 1 with %d hunks", len(files), len(fp.Hunks))
+	}
+
+	for i, hunk := range files[0].Hunks {
+		if hunk.ID != fp.Hunks[i].ID {
+			t.Errorf("hunk %d id = %q, want %q", i, hunk.ID, fp.Hunks[i].ID)
+		}
+	}
+}
+
+func TestParsePatchQuotedPaths(t *testing.T) {
+	patch := `diff --git "a/sp ace\303\251" "b/sp ace\303\251"
+index 1111111..2222222 100644
+--- "a/sp ace\303\251"
++++ "b/sp ace\303\251"
+@@ -1 +1 @@
+-a
++b
+`
+
+	files := ParsePatch(patch)
+	if len(files) != 1 {
+		t.Fatalf("files count = %d, want 1", len(files))
+	}
+
+	if files[0].Path != "sp aceé" || files[0].OldPath != "" {
+		t.Errorf("path = %q, old_path = %q, want %q and empty", files[0].Path, files[0].OldPath, "sp aceé")
+	}
+}
+
+func TestParsePatchUnquotedPathWithSpace(t *testing.T) {
+	patch := `diff --git a/x b/y b/x b/y
+new file mode 100644
+index 0000000..e69de29
+`
+
+	files := ParsePatch(patch)
+	if len(files) != 1 {
+		t.Fatalf("files count = %d, want 1", len(files))
+	}
+
+	if files[0].Path != "x b/y" || files[0].Status != "added" {
+		t.Errorf("file = %+v, want added %q", files[0], "x b/y")
+	}
+}
+
+func TestParsePatchEmpty(t *testing.T) {
+	files := ParsePatch("")
+	if files == nil || len(files) != 0 {
+		t.Errorf("files = %v, want empty slice", files)
+	}
+}
@@ -0,0 +1,74 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const stashRecordSep = "\x1e"
+
+const StashListFormat = "%gd" + fieldSep + "%H" + fieldSep + "%gs" + fieldSep + "%cI" + stashRecordSep
+
+var stashIndexRegexp = regexp.MustCompile(`^stash@\{(\d+)\}$`)
+
+func ParseStashList(output string) []StashEntry {
+	var entries []StashEntry
+
+	records := strings.Split(output, stashRecordSep)
+	for _, record := range records {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSep, 4)
+		if len(fields) < 4 {
+			continue
+		}
+
+		ref := strings.TrimSpace(fields[0])
+		branch, message := parseStashSubject(strings.TrimSpace(fields[2]))
+
+		entry := StashEntry{
+			Ref:     ref,
+			Hash:    strings.TrimSpace(fields[1]),
+			Branch:  branch,
+			Message: message,
+			Date:    strings.TrimSpace(fields[3]),
+		}
+
+		if matches := stashIndexRegexp.FindStringSubmatch(ref); matches != nil {
+			entry.Index, _ = strconv.Atoi(matches[1])
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if entries == nil {
+		entries = []StashEntry{}
+	}
+
+	return entries
+}
+
+// parseStashSubject splits a stash reflog subject such as
+// "WIP on main: abc1234 subject" or "On main: message" into its branch and
+// message parts.
+func parseStashSubject(subject string) (string, string) {
+	rest, ok := strings.CutPrefix(subject, "WIP on ")
+	if !ok {
+		rest, ok = strings.CutPrefix(subject, "On ")
+	}
+
+	if !ok {
+		return "", subject
+	}
+
+	branch, message, found := strings.Cut(rest, ": ")
+	if !found {
+		return "", subject
+	}
+
+	return branch, message
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseStashList(t *testing.T) {
+	input := "stash@{0}\x1fabc123\x1fWIP on main: def4567 Initial commit\x1f2024-01-15T10:30:00-05:00\x1e\nstash@{1}\x1f789abc\x1fOn feature: half-done refactor\x1f2024-01-14T09:00:00-05:00\x1e\n"
+
+	entries := ParseStashList(input)
+
+	if len(entries) != 2 {
+		t.Fatalf("entries count = %d, want 2", len(entries))
+	}
+
+	if entries[0].Index != 0 {
+		t.Errorf("entry 0 index = %d, want 0", entries[0].Index)
+	}
+
+	if entries[0].Ref != "stash@{0}" {
+		t.Errorf("entry 0 ref = %q, want %q", entries[0].Ref, "stash@{0}")
+	}
+
+	if entries[0].Hash != "abc123" {
+		t.Errorf("entry 0 hash = %q, want %q", entries[0].Hash, "abc123")
+	}
+
+	if entries[0].Branch != "main" {
+		t.Errorf("entry 0 branch = %q, want %q", entries[0].Branch, "main")
+	}
+
+	if entries[0].Message != "def4567 Initial commit" {
+		t.Errorf("entry 0 message = %q, want %q", entries[0].Message, "def4567 Initial commit")
+	}
+
+	if entries[0].Date != "2024-01-15T10:30:00-05:00" {
+		t.Errorf("entry 0 date = %q, want %q", entries[0].Date, "2024-01-15T10:30:00-05:00")
+	}
+
+	if entries[1].Index != 1 {
+		t.Errorf("entry 1 index = %d, want 1", entries[1].Index)
+	}
+
+	if entries[1].Branch != "feature" {
+		t.Errorf("entry 1 branch = %q, want %q", entries[1].Branch, "feature")
+	}
+
+	if entries[1].Message != "half-done refactor" {
+		t.Errorf("entry 1 message = %q, want %q", entries[1].Message, "half-done refactor")
+	}
+}
+
+func TestParseStashListUnrecognizedSubject(t *testing.T) {
+	input := "stash@{0}\x1fabc123\x1fautostash\x1f2024-01-15T10:30:00-05:00\x1e"
+
+	entries := ParseStashList(input)
+
+	if len(entries) != 1 {
+		t.Fatalf("entries count = %d, want 1", len(entries))
+	}
+
+	if entries[0].Branch != "" {
+		t.Errorf("branch = %q, want empty", entries[0].Branch)
+	}
+
+	if entries[0].Message != "autostash" {
+		t.Errorf("message = %q, want %q", entries[0].Message, "autostash")
+	}
+}
+
+func TestParseStashListEmpty(t *testing.T) {
+	entries := ParseStashList("")
+
+	if len(entries) != 0 {
+		t.Errorf("entries count = %d, want 0", len(entries))
+	}
+}
@@ -5,24 +5,40 @@ import (
 	"strings"
 )
 
+// ParseStatus parses the output of git status --porcelain=v2 -z. Records
+// are NUL-terminated and paths are not quoted, so paths containing spaces,
+// tabs, or newlines come through intact; a rename or copy record is followed
+// by a separate record holding the original path.
 func ParseStatus(output string) StatusResult {
 	result := StatusResult{
 		Entries: []StatusEntry{},
 	}
 
-	for _, line := range strings.Split(output, "\n") {
-		if line == "" {
+	records := strings.Split(output, "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if record == "" {
+			continue
+		}
+
+		if strings.HasPrefix(record, "# ") {
+			parseStatusHeader(record, &result.Branch)
 			continue
 		}
 
-		if strings.HasPrefix(line, "# ") {
-			parseStatusHeader(line, &result.Branch)
+		entry, ok := parseStatusEntry(record)
+		if !ok {
 			continue
 		}
 
-		if entry, ok := parseStatusEntry(line); ok {
-			result.Entries = append(result.Entries, entry)
+		if entry.Type == "renamed" || entry.Type == "copied" {
+			if i+1 < len(records) {
+				i++
+				entry.OrigPath = records[i]
+			}
 		}
+
+		result.Entries = append(result.Entries, entry)
 	}
 
 	return result
@@ -44,6 +60,8 @@ func parseStatusHeader(line string, branch *BranchStatus) {
 		branch.Head = value
 	case "branch.upstream":
 		branch.Upstream = value
+	case "stash":
+		branch.Stash, _ = strconv.Atoi(value)
 	case "branch.ab":
 		abParts := strings.Fields(value)
 		for _, p := range abParts {
@@ -56,99 +74,145 @@ func parseStatusHeader(line string, branch *BranchStatus) {
 	}
 }
 
-func parseStatusEntry(line string) (StatusEntry, bool) {
-	if len(line) < 2 {
+func parseStatusEntry(record string) (StatusEntry, bool) {
+	if len(record) < 3 {
 		return StatusEntry{}, false
 	}
 
-	prefix := string(line[0])
-
-	switch prefix {
-	case "1":
-		return parseOrdinaryEntry(line)
-	case "2":
-		return parseRenameEntry(line)
-	case "?":
-		return parseUntrackedEntry(line)
-	case "!":
-		return parseIgnoredEntry(line)
+	switch record[0] {
+	case '1':
+		return parseOrdinaryEntry(record)
+	case '2':
+		return parseRenameEntry(record)
+	case 'u':
+		return parseUnmergedEntry(record)
+	case '?':
+		return StatusEntry{Type: "untracked", State: "?", Worktree: "untracked", Path: record[2:]}, true
+	case '!':
+		return StatusEntry{Type: "ignored", State: "!", Worktree: "ignored", Path: record[2:]}, true
 	}
 
 	return StatusEntry{}, false
 }
 
-func parseOrdinaryEntry(line string) (StatusEntry, bool) {
-	fields := strings.Fields(line)
+// parseOrdinaryEntry parses "1 XY sub mH mI mW hH hI path". The path is
+// everything after the eighth space, so it may itself contain spaces.
+func parseOrdinaryEntry(record string) (StatusEntry, bool) {
+	fields := strings.SplitN(record, " ", 9)
 	if len(fields) < 9 {
 		return StatusEntry{}, false
 	}
 
-	return StatusEntry{
-		State: fields[1],
-		Path:  fields[8],
-	}, true
+	entry := newChangedEntry("ordinary", fields[1], fields[2])
+	entry.HeadMode = fields[3]
+	entry.IndexMode = fields[4]
+	entry.WorktreeMode = fields[5]
+	entry.HeadOID = fields[6]
+	entry.IndexOID = fields[7]
+	entry.Path = fields[8]
+
+	return entry, true
 }
 
-func parseRenameEntry(line string) (StatusEntry, bool) {
-	// Porcelain v2 rename format:
-	// 2 XY sub mH mI mW hH hI X### path\torigPath
-	// We need to skip 9 space-separated fields to reach the tab-separated paths.
-	fields := strings.Fields(line)
-	if len(fields) < 9 {
+// parseRenameEntry parses "2 XY sub mH mI mW hH hI Xscore path", where X is
+// R or C and score is the similarity percentage. The original path is the
+// next record.
+func parseRenameEntry(record string) (StatusEntry, bool) {
+	fields := strings.SplitN(record, " ", 10)
+	if len(fields) < 10 || len(fields[8]) < 2 {
 		return StatusEntry{}, false
 	}
 
-	state := fields[1]
-
-	spaceCount := 0
-	pathStart := 0
-	for i, ch := range line {
-		if ch == ' ' {
-			spaceCount++
-			if spaceCount == 9 {
-				pathStart = i + 1
-				break
-			}
-		}
+	recordType := "renamed"
+	if fields[8][0] == 'C' {
+		recordType = "copied"
 	}
 
-	if pathStart == 0 {
+	entry := newChangedEntry(recordType, fields[1], fields[2])
+	entry.HeadMode = fields[3]
+	entry.IndexMode = fields[4]
+	entry.WorktreeMode = fields[5]
+	entry.HeadOID = fields[6]
+	entry.IndexOID = fields[7]
+	entry.Similarity, _ = strconv.Atoi(fields[8][1:])
+	entry.Path = fields[9]
+
+	return entry, true
+}
+
+// parseUnmergedEntry parses "u XY sub m1 m2 m3 mW h1 h2 h3 path", where
+// stages 1, 2 and 3 are the common ancestor, ours, and theirs. A stage
+// missing on one side has mode 000000 and is left out.
+func parseUnmergedEntry(record string) (StatusEntry, bool) {
+	fields := strings.SplitN(record, " ", 11)
+	if len(fields) < 11 {
 		return StatusEntry{}, false
 	}
 
-	paths := strings.SplitN(line[pathStart:], "\t", 2)
-	entry := StatusEntry{
-		State: state,
-		Path:  paths[0],
-	}
+	entry := newChangedEntry("unmerged", fields[1], fields[2])
+	entry.Conflict = conflictTypes[fields[1]]
+	entry.WorktreeMode = fields[6]
+	entry.Path = fields[10]
 
-	if len(paths) > 1 {
-		entry.OrigPath = paths[1]
+	for i := 0; i < 3; i++ {
+		mode, oid := fields[3+i], fields[7+i]
+		if mode == "000000" {
+			continue
+		}
+		entry.Stages = append(entry.Stages, StatusStage{Stage: i + 1, Mode: mode, OID: oid})
 	}
 
 	return entry, true
 }
 
-func parseUntrackedEntry(line string) (StatusEntry, bool) {
-	if len(line) < 3 {
-		return StatusEntry{}, false
+func newChangedEntry(recordType, xy, sub string) StatusEntry {
+	entry := StatusEntry{
+		Type:      recordType,
+		State:     xy,
+		Submodule: parseSubmoduleState(sub),
 	}
 
-	return StatusEntry{
-		State: "?",
-		Path:  line[2:],
-	}, true
+	if len(xy) == 2 {
+		entry.Index = statusCodes[xy[0]]
+		entry.Worktree = statusCodes[xy[1]]
+	}
+
+	return entry
 }
 
-func parseIgnoredEntry(line string) (StatusEntry, bool) {
-	if len(line) < 3 {
-		return StatusEntry{}, false
+// parseSubmoduleState parses the "N..." or "S<c><m><u>" field, returning
+// nil for entries that are not submodules.
+func parseSubmoduleState(sub string) *SubmoduleState {
+	if len(sub) != 4 || sub[0] != 'S' {
+		return nil
+	}
+
+	return &SubmoduleState{
+		CommitChanged:    sub[1] == 'C',
+		TrackedChanges:   sub[2] == 'M',
+		UntrackedChanges: sub[3] == 'U',
 	}
+}
+
+var statusCodes = map[byte]string{
+	'.': "unmodified",
+	'M': "modified",
+	'T': "type_changed",
+	'A': "added",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'U': "unmerged",
+}
 
-	return StatusEntry{
-		State: "!",
-		Path:  line[2:],
-	}, true
+var conflictTypes = map[string]string{
+	"DD": "both_deleted",
+	"AU": "added_by_us",
+	"UD": "deleted_by_them",
+	"UA": "added_by_them",
+	"DU": "deleted_by_us",
+	"AA": "both_added",
+	"UU": "both_modified",
 }
 
 func ParseDiffNumstat(output string) []DiffStat {
@@ -1,18 +1,18 @@
 package git
 
 import (
+	"reflect"
 	"testing"
 )
 
 func TestParseStatus(t *testing.T) {
-	input := `# branch.oid abc123def456
-# branch.head main
-# branch.upstream origin/main
-# branch.ab +2 -1
-1 .M N... 100644 100644 100644 abc123 def456 file.go
-1 M. N... 100644 100644 100644 abc123 def456 staged.go
-? untracked.txt
-`
+	input := "# branch.oid abc123def456\x00" +
+		"# branch.head main\x00" +
+		"# branch.upstream origin/main\x00" +
+		"# branch.ab +2 -1\x00" +
+		"1 .M N... 100644 100644 100644 abc123 def456 file.go\x00" +
+		"1 M. N... 100644 100644 100644 abc123 def456 staged.go\x00" +
+		"? untracked.txt\x00"
 
 	result := ParseStatus(input)
 
@@ -61,10 +61,16 @@ func TestParseStatus(t *testing.T) {
 	}
 }
 
+func TestParseStatusStash(t *testing.T) {
+	result := ParseStatus("# branch.oid abc123\x00# branch.head main\x00# stash 3\x00")
+
+	if result.Branch.Stash != 3 {
+		t.Errorf("branch stash = %d, want 3", result.Branch.Stash)
+	}
+}
+
 func TestParseStatusEmpty(t *testing.T) {
-	input := `# branch.oid abc123
-# branch.head main
-`
+	input := "# branch.oid abc123\x00# branch.head main\x00"
 
 	result := ParseStatus(input)
 
@@ -74,10 +80,9 @@ func TestParseStatusEmpty(t *testing.T) {
 }
 
 func TestParseStatusRename(t *testing.T) {
-	input := `# branch.oid abc123
-# branch.head main
-2 R. N... 100644 100644 100644 abc123 def456 R100 new.go	old.go
-`
+	input := "# branch.oid abc123\x00" +
+		"# branch.head main\x00" +
+		"2 R. N... 100644 100644 100644 abc123 def456 R100 new.go\x00old.go\x00"
 
 	result := ParseStatus(input)
 
@@ -96,6 +101,129 @@ func TestParseStatusRename(t *testing.T) {
 	if result.Entries[0].OrigPath != "old.go" {
 		t.Errorf("entry orig_path = %q, want %q", result.Entries[0].OrigPath, "old.go")
 	}
+
+	if result.Entries[0].Type != "renamed" || result.Entries[0].Similarity != 100 {
+		t.Errorf("entry type, similarity = %q, %d, want renamed, 100", result.Entries[0].Type, result.Entries[0].Similarity)
+	}
+}
+
+func TestParseStatusPathsWithSpaces(t *testing.T) {
+	input := "1 .M N... 100644 100644 100644 abc123 def456 dir name/file name.go\x00" +
+		"2 C. N... 100644 100644 100644 abc123 def456 C75 copy of a.go\x00a b.go\x00" +
+		"? new file.txt\x00" +
+		"! build output/x.o\x00"
+
+	result := ParseStatus(input)
+
+	want := []StatusEntry{
+		{Path: "dir name/file name.go"},
+		{Path: "copy of a.go", OrigPath: "a b.go"},
+		{Path: "new file.txt"},
+		{Path: "build output/x.o"},
+	}
+
+	if len(result.Entries) != len(want) {
+		t.Fatalf("entries count = %d, want %d", len(result.Entries), len(want))
+	}
+
+	for i, w := range want {
+		got := result.Entries[i]
+		if got.Path != w.Path || got.OrigPath != w.OrigPath {
+			t.Errorf("entry %d = %q <- %q, want %q <- %q", i, got.Path, got.OrigPath, w.Path, w.OrigPath)
+		}
+	}
+
+	if result.Entries[1].Type != "copied" || result.Entries[1].Similarity != 75 {
+		t.Errorf("entry 1 type, similarity = %q, %d, want copied, 75", result.Entries[1].Type, result.Entries[1].Similarity)
+	}
+
+	if result.Entries[2].Type != "untracked" || result.Entries[3].Type != "ignored" {
+		t.Errorf("entry types = %q, %q, want untracked, ignored", result.Entries[2].Type, result.Entries[3].Type)
+	}
+}
+
+func TestParseStatusOrdinaryFields(t *testing.T) {
+	input := "1 MD N... 100644 100755 000000 1111111 2222222 run.sh\x00"
+
+	result := ParseStatus(input)
+
+	if len(result.Entries) != 1 {
+		t.Fatalf("entries count = %d, want 1", len(result.Entries))
+	}
+
+	got := result.Entries[0]
+	want := StatusEntry{
+		Type:         "ordinary",
+		State:        "MD",
+		Index:        "modified",
+		Worktree:     "deleted",
+		Path:         "run.sh",
+		HeadMode:     "100644",
+		IndexMode:    "100755",
+		WorktreeMode: "000000",
+		HeadOID:      "1111111",
+		IndexOID:     "2222222",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("entry = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseStatusUnmerged(t *testing.T) {
+	input := "u UU N... 100644 100644 100644 100644 aaa111 bbb222 ccc333 conflicted file.txt\x00" +
+		"u DU N... 100644 000000 100644 100644 aaa111 0000000 ccc333 gone.txt\x00"
+
+	result := ParseStatus(input)
+
+	if len(result.Entries) != 2 {
+		t.Fatalf("entries count = %d, want 2", len(result.Entries))
+	}
+
+	both := result.Entries[0]
+	if both.Type != "unmerged" || both.Conflict != "both_modified" || both.Path != "conflicted file.txt" {
+		t.Errorf("entry 0 = %+v, want both_modified conflicted file.txt", both)
+	}
+
+	wantStages := []StatusStage{
+		{Stage: 1, Mode: "100644", OID: "aaa111"},
+		{Stage: 2, Mode: "100644", OID: "bbb222"},
+		{Stage: 3, Mode: "100644", OID: "ccc333"},
+	}
+	if !reflect.DeepEqual(both.Stages, wantStages) {
+		t.Errorf("entry 0 stages = %+v, want %+v", both.Stages, wantStages)
+	}
+
+	deleted := result.Entries[1]
+	if deleted.Conflict != "deleted_by_us" || len(deleted.Stages) != 2 || deleted.Stages[1].Stage != 3 {
+		t.Errorf("entry 1 = %+v, want deleted_by_us with stages 1 and 3", deleted)
+	}
+}
+
+func TestParseStatusSubmodule(t *testing.T) {
+	input := "1 .M SCMU 160000 160000 160000 abc123 abc123 vendor/lib\x00" +
+		"1 .M S.M. 160000 160000 160000 abc123 abc123 vendor/other\x00" +
+		"1 .M N... 100644 100644 100644 abc123 abc123 plain.go\x00"
+
+	result := ParseStatus(input)
+
+	if len(result.Entries) != 3 {
+		t.Fatalf("entries count = %d, want 3", len(result.Entries))
+	}
+
+	want := &SubmoduleState{CommitChanged: true, TrackedChanges: true, UntrackedChanges: true}
+	if !reflect.DeepEqual(result.Entries[0].Submodule, want) {
+		t.Errorf("entry 0 submodule = %+v, want %+v", result.Entries[0].Submodule, want)
+	}
+
+	want = &SubmoduleState{TrackedChanges: true}
+	if !reflect.DeepEqual(result.Entries[1].Submodule, want) {
+		t.Errorf("entry 1 submodule = %+v, want %+v", result.Entries[1].Submodule, want)
+	}
+
+	if result.Entries[2].Submodule != nil {
+		t.Errorf("entry 2 submodule = %+v, want nil", result.Entries[2].Submodule)
+	}
 }
 
 func TestParseDiffNumstat(t *testing.T) {
@@ -0,0 +1,79 @@
+package git
+
+import (
+	"strings"
+)
+
+const tagRecordSep = "\x1e"
+
+const TagListFormat = "%(refname:short)" + fieldSep +
+	"%(objecttype)" + fieldSep +
+	"%(objectname)" + fieldSep +
+	"%(*objectname)" + fieldSep +
+	"%(creatordate:iso-strict)" + fieldSep +
+	"%(taggername)" + fieldSep +
+	"%(taggeremail)" + fieldSep +
+	"%(taggerdate:iso-strict)" + fieldSep +
+	"%(contents:subject)" + fieldSep +
+	"%(contents:body)" + tagRecordSep
+
+func ParseTagList(output string) []TagEntry {
+	var tags []TagEntry
+
+	records := strings.Split(output, tagRecordSep)
+	for _, record := range records {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSep, 10)
+		if len(fields) < 5 {
+			continue
+		}
+
+		entry := TagEntry{
+			Name:      strings.TrimSpace(fields[0]),
+			Hash:      strings.TrimSpace(fields[2]),
+			Commit:    strings.TrimSpace(fields[3]),
+			Annotated: strings.TrimSpace(fields[1]) == "tag",
+			Date:      strings.TrimSpace(fields[4]),
+		}
+
+		// Lightweight tags point directly at the commit, and their
+		// contents are the commit message rather than a tag message.
+		if !entry.Annotated {
+			entry.Commit = entry.Hash
+			tags = append(tags, entry)
+			continue
+		}
+
+		if len(fields) > 5 {
+			entry.TaggerName = strings.TrimSpace(fields[5])
+		}
+
+		if len(fields) > 6 {
+			entry.TaggerEmail = strings.Trim(strings.TrimSpace(fields[6]), "<>")
+		}
+
+		if len(fields) > 7 {
+			entry.TaggerDate = strings.TrimSpace(fields[7])
+		}
+
+		if len(fields) > 8 {
+			entry.Subject = strings.TrimSpace(fields[8])
+		}
+
+		if len(fields) > 9 {
+			entry.Body = strings.TrimSpace(fields[9])
+		}
+
+		tags = append(tags, entry)
+	}
+
+	if tags == nil {
+		tags = []TagEntry{}
+	}
+
+	return tags
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseTagList(t *testing.T) {
+	input := "lw\x1fcommit\x1fabc123\x1f\x1f2024-01-15T10:30:00-05:00\x1f\x1f\x1f\x1fCommit subject\x1f\x1e\n" +
+		"v1.0\x1ftag\x1fdef456\x1fabc123\x1f2024-01-16T09:00:00-05:00\x1fJane Smith\x1f<jane@example.com>\x1f2024-01-16T09:00:00-05:00\x1fRelease 1.0\x1fRelease notes\n\x1e\n"
+
+	tags := ParseTagList(input)
+
+	if len(tags) != 2 {
+		t.Fatalf("tags count = %d, want 2", len(tags))
+	}
+
+	if tags[0].Name != "lw" {
+		t.Errorf("tag 0 name = %q, want %q", tags[0].Name, "lw")
+	}
+
+	if tags[0].Annotated {
+		t.Error("tag 0 should be lightweight")
+	}
+
+	if tags[0].Commit != "abc123" {
+		t.Errorf("tag 0 commit = %q, want %q", tags[0].Commit, "abc123")
+	}
+
+	if tags[0].Subject != "" {
+		t.Errorf("tag 0 subject = %q, want empty for lightweight tag", tags[0].Subject)
+	}
+
+	if !tags[1].Annotated {
+		t.Error("tag 1 should be annotated")
+	}
+
+	if tags[1].Hash != "def456" {
+		t.Errorf("tag 1 hash = %q, want %q", tags[1].Hash, "def456")
+	}
+
+	if tags[1].Commit != "abc123" {
+		t.Errorf("tag 1 commit = %q, want %q", tags[1].Commit, "abc123")
+	}
+
+	if tags[1].TaggerName != "Jane Smith" {
+		t.Errorf("tag 1 tagger = %q, want %q", tags[1].TaggerName, "Jane Smith")
+	}
+
+	if tags[1].TaggerEmail != "jane@example.com" {
+		t.Errorf("tag 1 tagger email = %q, want %q", tags[1].TaggerEmail, "jane@example.com")
+	}
+
+	if tags[1].Subject != "Release 1.0" {
+		t.Errorf("tag 1 subject = %q, want %q", tags[1].Subject, "Release 1.0")
+	}
+
+	if tags[1].Body != "Release notes" {
+		t.Errorf("tag 1 body = %q, want %q", tags[1].Body, "Release notes")
+	}
+}
+
+func TestParseTagListEmpty(t *testing.T) {
+	tags := ParseTagList("")
+
+	if len(tags) != 0 {
+		t.Errorf("tags count = %d, want 0", len(tags))
+	}
+}
@@ -0,0 +1,61 @@
+package git
+
+import (
+	"strings"
+)
+
+func ParseWorktreeList(output string) []WorktreeEntry {
+	var worktrees []WorktreeEntry
+	var current *WorktreeEntry
+
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			if current != nil {
+				worktrees = append(worktrees, *current)
+				current = nil
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+
+		if key == "worktree" {
+			if current != nil {
+				worktrees = append(worktrees, *current)
+			}
+			current = &WorktreeEntry{Path: value}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		switch key {
+		case "HEAD":
+			current.Head = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			current.Bare = true
+		case "detached":
+			current.Detached = true
+		case "locked":
+			current.Locked = true
+			current.LockReason = value
+		case "prunable":
+			current.Prunable = true
+			current.PrunableReason = value
+		}
+	}
+
+	if current != nil {
+		worktrees = append(worktrees, *current)
+	}
+
+	if worktrees == nil {
+		worktrees = []WorktreeEntry{}
+	}
+
+	return worktrees
+}
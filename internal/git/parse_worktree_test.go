@@ -0,0 +1,87 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	input := `worktree /home/user/repo
+HEAD abc123def456
+branch refs/heads/main
+
+worktree /home/user/repo-worktrees/feature
+HEAD def789abc123
+branch refs/heads/feature/login
+
+worktree /home/user/repo-worktrees/scratch
+HEAD 123456abcdef
+detached
+locked agent session running
+prunable gitdir file points to non-existent location
+
+`
+
+	worktrees := ParseWorktreeList(input)
+
+	if len(worktrees) != 3 {
+		t.Fatalf("worktrees count = %d, want 3", len(worktrees))
+	}
+
+	if worktrees[0].Path != "/home/user/repo" {
+		t.Errorf("worktree 0 path = %q, want %q", worktrees[0].Path, "/home/user/repo")
+	}
+
+	if worktrees[0].Head != "abc123def456" {
+		t.Errorf("worktree 0 head = %q, want %q", worktrees[0].Head, "abc123def456")
+	}
+
+	if worktrees[0].Branch != "main" {
+		t.Errorf("worktree 0 branch = %q, want %q", worktrees[0].Branch, "main")
+	}
+
+	if worktrees[1].Branch != "feature/login" {
+		t.Errorf("worktree 1 branch = %q, want %q", worktrees[1].Branch, "feature/login")
+	}
+
+	if !worktrees[2].Detached {
+		t.Error("worktree 2 should be detached")
+	}
+
+	if !worktrees[2].Locked {
+		t.Error("worktree 2 should be locked")
+	}
+
+	if worktrees[2].LockReason != "agent session running" {
+		t.Errorf("worktree 2 lock reason = %q, want %q", worktrees[2].LockReason, "agent session running")
+	}
+
+	if !worktrees[2].Prunable {
+		t.Error("worktree 2 should be prunable")
+	}
+
+	if worktrees[2].PrunableReason != "gitdir file points to non-existent location" {
+		t.Errorf("worktree 2 prunable reason = %q", worktrees[2].PrunableReason)
+	}
+}
+
+func TestParseWorktreeListBare(t *testing.T) {
+	input := "worktree /srv/repo.git\nbare\n"
+
+	worktrees := ParseWorktreeList(input)
+
+	if len(worktrees) != 1 {
+		t.Fatalf("worktrees count = %d, want 1", len(worktrees))
+	}
+
+	if !worktrees[0].Bare {
+		t.Error("worktree 0 should be bare")
+	}
+}
+
+func TestParseWorktreeListEmpty(t *testing.T) {
+	worktrees := ParseWorktreeList("")
+
+	if len(worktrees) != 0 {
+		t.Errorf("worktrees count = %d, want 0", len(worktrees))
+	}
+}
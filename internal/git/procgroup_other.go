@@ -0,0 +1,9 @@
+//go:build !unix
+
+package git
+
+import "os/exec"
+
+// setProcessGroup leaves cmd's default cancellation, which kills only git
+// itself, on platforms without process groups.
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,124 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RebaseStep is one line of a declarative rebase plan.
+type RebaseStep struct {
+	Action  string `json:"action"`
+	Commit  string `json:"commit"`
+	Message string `json:"message,omitempty"`
+}
+
+// UnmarshalJSON accepts either an object or a todo-style string such as
+// "reword abc1234 New subject", since MCP array params are strings.
+func (s *RebaseStep) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var line string
+		if err := json.Unmarshal(data, &line); err != nil {
+			return err
+		}
+
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if len(fields) < 2 {
+			return fmt.Errorf("invalid rebase step %q: want \"<action> <commit> [message]\"", line)
+		}
+
+		s.Action = fields[0]
+		s.Commit = fields[1]
+		if len(fields) == 3 {
+			s.Message = strings.TrimSpace(fields[2])
+		}
+
+		return nil
+	}
+
+	type plain RebaseStep
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+var rebaseActions = map[string]bool{
+	"pick":   true,
+	"reword": true,
+	"squash": true,
+	"fixup":  true,
+	"drop":   true,
+}
+
+// ValidateRebasePlan checks the actions and messages of a rebase plan.
+func ValidateRebasePlan(steps []RebaseStep) error {
+	if len(steps) == 0 {
+		return fmt.Errorf("rebase plan is empty")
+	}
+
+	kept := false
+	for i, step := range steps {
+		if !rebaseActions[step.Action] {
+			return fmt.Errorf("step %d: invalid action %q: must be pick, reword, squash, fixup, or drop", i+1, step.Action)
+		}
+
+		if step.Commit == "" {
+			return fmt.Errorf("step %d: commit is required", i+1)
+		}
+
+		switch step.Action {
+		case "reword":
+			if step.Message == "" {
+				return fmt.Errorf("step %d: reword requires a message", i+1)
+			}
+		case "pick", "drop":
+			if step.Message != "" {
+				return fmt.Errorf("step %d: message is only allowed for reword, squash, and fixup", i+1)
+			}
+		case "squash", "fixup":
+			if !kept {
+				return fmt.Errorf("step %d: cannot %s without a previous commit", i+1, step.Action)
+			}
+		}
+
+		if step.Action != "drop" {
+			kept = true
+		}
+	}
+
+	return nil
+}
+
+// BuildRebaseTodo renders a validated plan as a git-rebase-todo file.
+// Commits must already be resolved to full hashes. Steps with a message get
+// an exec line that amends the resulting commit with the message stored at
+// messagePaths[i], so no editor is needed.
+func BuildRebaseTodo(steps []RebaseStep, messagePaths map[int]string) string {
+	var b strings.Builder
+
+	for i, step := range steps {
+		action := step.Action
+		if action == "reword" {
+			// The message is applied by the exec line below instead of
+			// git's reword, which would open an editor.
+			action = "pick"
+		}
+
+		fmt.Fprintf(&b, "%s %s\n", action, step.Commit)
+
+		if path, ok := messagePaths[i]; ok {
+			fmt.Fprintf(&b, "exec git commit --amend --only --quiet --file=%s\n", shellQuote(path))
+		}
+	}
+
+	return b.String()
+}
+
+// shellQuote quotes s for the shell git runs editors and exec lines with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// SequenceEditorEnv returns a GIT_SEQUENCE_EDITOR setting that replaces the
+// todo list git generates with the file at todoPath.
+func SequenceEditorEnv(todoPath string) string {
+	return "GIT_SEQUENCE_EDITOR=cp " + shellQuote(todoPath)
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRebaseStepUnmarshal(t *testing.T) {
+	var steps []RebaseStep
+	input := `["reword abc123 New subject line", {"action": "squash", "commit": "def456", "message": "Combined"}, "drop 789abc"]`
+
+	if err := json.Unmarshal([]byte(input), &steps); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	want := []RebaseStep{
+		{Action: "reword", Commit: "abc123", Message: "New subject line"},
+		{Action: "squash", Commit: "def456", Message: "Combined"},
+		{Action: "drop", Commit: "789abc"},
+	}
+
+	if len(steps) != len(want) {
+		t.Fatalf("steps count = %d, want %d", len(steps), len(want))
+	}
+
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("step %d = %+v, want %+v", i, steps[i], want[i])
+		}
+	}
+}
+
+func TestRebaseStepUnmarshalInvalid(t *testing.T) {
+	var step RebaseStep
+	if err := json.Unmarshal([]byte(`"pick"`), &step); err == nil {
+		t.Errorf("expected error for step without commit")
+	}
+}
+
+func TestValidateRebasePlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []RebaseStep
+		wantErr string
+	}{
+		{
+			name:  "valid plan",
+			steps: []RebaseStep{{Action: "pick", Commit: "a"}, {Action: "fixup", Commit: "b"}, {Action: "reword", Commit: "c", Message: "m"}},
+		},
+		{
+			name:    "empty plan",
+			wantErr: "empty",
+		},
+		{
+			name:    "unknown action",
+			steps:   []RebaseStep{{Action: "edit", Commit: "a"}},
+			wantErr: "invalid action",
+		},
+		{
+			name:    "reword without message",
+			steps:   []RebaseStep{{Action: "reword", Commit: "a"}},
+			wantErr: "requires a message",
+		},
+		{
+			name:    "message on pick",
+			steps:   []RebaseStep{{Action: "pick", Commit: "a", Message: "m"}},
+			wantErr: "only allowed",
+		},
+		{
+			name:    "squash first",
+			steps:   []RebaseStep{{Action: "drop", Commit: "a"}, {Action: "squash", Commit: "b"}},
+			wantErr: "without a previous commit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRebasePlan(tt.steps)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildRebaseTodo(t *testing.T) {
+	steps := []RebaseStep{
+		{Action: "pick", Commit: "aaa"},
+		{Action: "reword", Commit: "bbb", Message: "new"},
+		{Action: "squash", Commit: "ccc"},
+		{Action: "drop", Commit: "ddd"},
+	}
+
+	got := BuildRebaseTodo(steps, map[int]string{1: "/tmp/it's/step-2"})
+
+	want := `pick aaa
+pick bbb
+exec git commit --amend --only --quiet --file='/tmp/it'\''s/step-2'
+squash ccc
+drop ddd
+`
+
+	if got != want {
+		t.Errorf("todo =\n%s\nwant\n%s", got, want)
+	}
+}
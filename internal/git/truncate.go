@@ -18,3 +18,36 @@ func TruncatePatch(patch string, maxLines int) (string, bool, int) {
 
 	return strings.Join(lines[:maxLines], "\n"), true, maxLines
 }
+
+// TruncateFiles limits structured patch files to maxLines lines, counting
+// each hunk header and hunk line. Truncation only happens at hunk boundaries:
+// the hunk that would exceed the limit and everything after it is dropped.
+// Returns the kept files, whether truncation occurred, and the number of
+// lines kept. If maxLines is 0, no truncation is performed.
+func TruncateFiles(files []PatchFile, maxLines int) ([]PatchFile, bool, int) {
+	if maxLines <= 0 {
+		return files, false, 0
+	}
+
+	kept := make([]PatchFile, 0, len(files))
+	total := 0
+
+	for _, file := range files {
+		hunks := make([]PatchHunk, 0, len(file.Hunks))
+
+		for _, hunk := range file.Hunks {
+			size := 1 + len(hunk.Lines)
+			if total+size > maxLines {
+				file.Hunks = hunks
+				return append(kept, file), true, total
+			}
+
+			total += size
+			hunks = append(hunks, hunk)
+		}
+
+		kept = append(kept, file)
+	}
+
+	return kept, false, 0
+}
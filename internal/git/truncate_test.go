@@ -98,3 +98,83 @@ func splitLines(s string) []string {
 	}
 	return lines
 }
+
+func TestTruncateFiles(t *testing.T) {
+	hunk := func(lines int) PatchHunk {
+		return PatchHunk{Lines: make([]PatchLine, lines)}
+	}
+
+	files := []PatchFile{
+		{Path: "a", Hunks: []PatchHunk{hunk(3), hunk(3)}},
+		{Path: "b", Hunks: []PatchHunk{hunk(2)}},
+	}
+
+	tests := []struct {
+		name          string
+		maxLines      int
+		wantTruncated bool
+		wantLine      int
+		wantHunks     []int
+	}{
+		{
+			name:          "no truncation when maxLines is 0",
+			maxLines:      0,
+			wantTruncated: false,
+			wantLine:      0,
+			wantHunks:     []int{2, 1},
+		},
+		{
+			name:          "no truncation when exactly at limit",
+			maxLines:      11,
+			wantTruncated: false,
+			wantLine:      0,
+			wantHunks:     []int{2, 1},
+		},
+		{
+			name:          "cuts before the hunk that does not fit",
+			maxLines:      6,
+			wantTruncated: true,
+			wantLine:      4,
+			wantHunks:     []int{1},
+		},
+		{
+			name:          "drops later files",
+			maxLines:      9,
+			wantTruncated: true,
+			wantLine:      8,
+			wantHunks:     []int{2, 0},
+		},
+		{
+			name:          "first hunk too large",
+			maxLines:      2,
+			wantTruncated: true,
+			wantLine:      0,
+			wantHunks:     []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated, line := TruncateFiles(files, tt.maxLines)
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if line != tt.wantLine {
+				t.Errorf("line = %d, want %d", line, tt.wantLine)
+			}
+
+			if len(got) != len(tt.wantHunks) {
+				t.Fatalf("files = %d, want %d", len(got), len(tt.wantHunks))
+			}
+			for i, want := range tt.wantHunks {
+				if len(got[i].Hunks) != want {
+					t.Errorf("file %d hunks = %d, want %d", i, len(got[i].Hunks), want)
+				}
+			}
+		})
+	}
+
+	if len(files[0].Hunks) != 2 {
+		t.Errorf("input was modified: file 0 hunks = %d, want 2", len(files[0].Hunks))
+	}
+}
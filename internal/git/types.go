@@ -6,17 +6,49 @@ type BranchStatus struct {
 	Upstream string `json:"upstream,omitempty"`
 	Ahead    int    `json:"ahead,omitempty"`
 	Behind   int    `json:"behind,omitempty"`
+	Stash    int    `json:"stash,omitempty"`
 }
 
 type StatusEntry struct {
-	State    string `json:"state"`
-	Path     string `json:"path"`
-	OrigPath string `json:"orig_path,omitempty"`
+	Type         string          `json:"type"`
+	State        string          `json:"state"`
+	Index        string          `json:"index,omitempty"`
+	Worktree     string          `json:"worktree,omitempty"`
+	Path         string          `json:"path"`
+	OrigPath     string          `json:"orig_path,omitempty"`
+	Similarity   int             `json:"similarity,omitempty"`
+	Conflict     string          `json:"conflict,omitempty"`
+	HeadMode     string          `json:"head_mode,omitempty"`
+	IndexMode    string          `json:"index_mode,omitempty"`
+	WorktreeMode string          `json:"worktree_mode,omitempty"`
+	HeadOID      string          `json:"head_oid,omitempty"`
+	IndexOID     string          `json:"index_oid,omitempty"`
+	Stages       []StatusStage   `json:"stages,omitempty"`
+	Submodule    *SubmoduleState `json:"submodule,omitempty"`
+}
+
+type StatusStage struct {
+	Stage int    `json:"stage"`
+	Mode  string `json:"mode"`
+	OID   string `json:"oid"`
+}
+
+type SubmoduleState struct {
+	CommitChanged    bool `json:"commit_changed"`
+	TrackedChanges   bool `json:"tracked_changes"`
+	UntrackedChanges bool `json:"untracked_changes"`
 }
 
 type StatusResult struct {
-	Branch  BranchStatus  `json:"branch"`
-	Entries []StatusEntry `json:"entries"`
+	Branch     BranchStatus          `json:"branch"`
+	InProgress []OperationInProgress `json:"in_progress,omitempty"`
+	Entries    []StatusEntry         `json:"entries"`
+}
+
+type OperationInProgress struct {
+	Operation string `json:"operation"`
+	Backend   string `json:"backend,omitempty"`
+	Tool      string `json:"tool,omitempty"`
 }
 
 type DiffStat struct {
@@ -33,33 +65,71 @@ type DiffSummary struct {
 }
 
 type DiffResult struct {
-	Stats           []DiffStat  `json:"stats"`
-	Summary         DiffSummary `json:"summary"`
-	Patch           string      `json:"patch,omitempty"`
-	Truncated       bool        `json:"truncated,omitempty"`
-	TruncatedAtLine int         `json:"truncated_at_line,omitempty"`
+	Stats           []DiffStat       `json:"stats"`
+	Summary         DiffSummary      `json:"summary"`
+	Patch           string           `json:"patch,omitempty"`
+	Files           []PatchFile      `json:"files,omitempty"`
+	Truncated       bool             `json:"truncated,omitempty"`
+	TruncatedAtLine int              `json:"truncated_at_line,omitempty"`
+	TruncatedFiles  []FileTruncation `json:"truncated_files,omitempty"`
 }
 
 type LogEntry struct {
-	Hash        string `json:"hash"`
-	AuthorName  string `json:"author_name"`
-	AuthorEmail string `json:"author_email"`
-	AuthorDate  string `json:"author_date"`
-	Subject     string `json:"subject"`
-	Body        string `json:"body,omitempty"`
+	Hash           string           `json:"hash"`
+	Parents        []string         `json:"parents"`
+	AuthorName     string           `json:"author_name"`
+	AuthorEmail    string           `json:"author_email"`
+	AuthorDate     string           `json:"author_date"`
+	CommitterName  string           `json:"committer_name"`
+	CommitterEmail string           `json:"committer_email"`
+	CommitterDate  string           `json:"committer_date"`
+	Refs           *CommitRefs      `json:"refs,omitempty"`
+	Subject        string           `json:"subject"`
+	Body           string           `json:"body,omitempty"`
+	Trailers       []Trailer        `json:"trailers,omitempty"`
+	Signature      *CommitSignature `json:"signature,omitempty"`
+	Stats          []DiffStat       `json:"stats,omitempty"`
+}
+
+// CommitRefs are the refs pointing at a commit.
+type CommitRefs struct {
+	Head           bool     `json:"head,omitempty"`
+	Branches       []string `json:"branches,omitempty"`
+	RemoteBranches []string `json:"remote_branches,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Other          []string `json:"other,omitempty"`
+}
+
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type CommitSignature struct {
+	Status string `json:"status"`
+	Signer string `json:"signer,omitempty"`
+	Key    string `json:"key,omitempty"`
+}
+
+// LogResult is one page of commits. NextCursor is empty on the last page.
+type LogResult struct {
+	Commits    []LogEntry `json:"commits"`
+	NextCursor string     `json:"next_cursor,omitempty"`
 }
 
 type ShowResult struct {
-	Hash            string     `json:"hash"`
-	AuthorName      string     `json:"author_name"`
-	AuthorEmail     string     `json:"author_email"`
-	AuthorDate      string     `json:"author_date"`
-	Subject         string     `json:"subject"`
-	Body            string     `json:"body,omitempty"`
-	Stats           []DiffStat `json:"stats"`
-	Patch           string     `json:"patch,omitempty"`
-	Truncated       bool       `json:"truncated,omitempty"`
-	TruncatedAtLine int        `json:"truncated_at_line,omitempty"`
+	Hash            string           `json:"hash"`
+	AuthorName      string           `json:"author_name"`
+	AuthorEmail     string           `json:"author_email"`
+	AuthorDate      string           `json:"author_date"`
+	Subject         string           `json:"subject"`
+	Body            string           `json:"body,omitempty"`
+	Stats           []DiffStat       `json:"stats"`
+	Patch           string           `json:"patch,omitempty"`
+	Files           []PatchFile      `json:"files,omitempty"`
+	Truncated       bool             `json:"truncated,omitempty"`
+	TruncatedAtLine int              `json:"truncated_at_line,omitempty"`
+	TruncatedFiles  []FileTruncation `json:"truncated_files,omitempty"`
 }
 
 type BlameLine struct {
@@ -73,6 +143,11 @@ type BlameLine struct {
 	Content     string `json:"content"`
 }
 
+type BlameResult struct {
+	Lines      []BlameLine `json:"lines"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
 type BranchEntry struct {
 	Name      string `json:"name"`
 	Hash      string `json:"hash"`
@@ -82,6 +157,11 @@ type BranchEntry struct {
 	Track     string `json:"track,omitempty"`
 }
 
+type BranchListResult struct {
+	Branches   []BranchEntry `json:"branches"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
 type RemoteEntry struct {
 	Name     string `json:"name"`
 	FetchURL string `json:"fetch_url"`
@@ -94,10 +174,12 @@ type RevParseResult struct {
 }
 
 type CommitResult struct {
-	Status  string `json:"status"`
-	Branch  string `json:"branch"`
-	Hash    string `json:"hash"`
-	Subject string `json:"subject"`
+	Status  string      `json:"status"`
+	Branch  string      `json:"branch"`
+	Hash    string      `json:"hash"`
+	Subject string      `json:"subject"`
+	Stats   []DiffStat  `json:"stats,omitempty"`
+	Summary DiffSummary `json:"summary"`
 }
 
 type PullResult struct {
@@ -121,10 +203,167 @@ type MutationResult struct {
 }
 
 type RebaseResult struct {
-	Status      string   `json:"status"`
-	Branch      string   `json:"branch,omitempty"`
-	Upstream    string   `json:"upstream,omitempty"`
-	Conflicts   []string `json:"conflicts,omitempty"`
-	CurrentStep string   `json:"current_step,omitempty"`
-	Summary     string   `json:"summary,omitempty"`
+	Status      string          `json:"status"`
+	Branch      string          `json:"branch,omitempty"`
+	Upstream    string          `json:"upstream,omitempty"`
+	Conflicts   []string        `json:"conflicts,omitempty"`
+	CurrentStep string          `json:"current_step,omitempty"`
+	Commits     []AppliedCommit `json:"commits,omitempty"`
+	Summary     string          `json:"summary,omitempty"`
+}
+
+type StashEntry struct {
+	Index   int    `json:"index"`
+	Ref     string `json:"ref"`
+	Hash    string `json:"hash"`
+	Branch  string `json:"branch,omitempty"`
+	Message string `json:"message"`
+	Date    string `json:"date"`
+}
+
+type StashListResult struct {
+	Stashes    []StashEntry `json:"stashes"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type StashResult struct {
+	Status    string   `json:"status"`
+	Ref       string   `json:"ref,omitempty"`
+	Paths     []string `json:"paths,omitempty"`
+	Conflicts []string `json:"conflicts,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+}
+
+type TagEntry struct {
+	Name        string `json:"name"`
+	Hash        string `json:"hash"`
+	Commit      string `json:"commit"`
+	Annotated   bool   `json:"annotated"`
+	Date        string `json:"date,omitempty"`
+	TaggerName  string `json:"tagger_name,omitempty"`
+	TaggerEmail string `json:"tagger_email,omitempty"`
+	TaggerDate  string `json:"tagger_date,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+type TagListResult struct {
+	Tags       []TagEntry `json:"tags"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+type TagResult struct {
+	Status    string `json:"status"`
+	Name      string `json:"name"`
+	Ref       string `json:"ref,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Annotated bool   `json:"annotated,omitempty"`
+}
+
+type MergeResult struct {
+	Status    string   `json:"status"`
+	Branch    string   `json:"branch,omitempty"`
+	Ref       string   `json:"ref,omitempty"`
+	Hash      string   `json:"hash,omitempty"`
+	Conflicts []string `json:"conflicts,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+}
+
+type AppliedCommit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+}
+
+type SequencerResult struct {
+	Status        string          `json:"status"`
+	Operation     string          `json:"operation"`
+	Applied       []AppliedCommit `json:"applied,omitempty"`
+	CurrentCommit string          `json:"current_commit,omitempty"`
+	Conflicts     []string        `json:"conflicts,omitempty"`
+	Summary       string          `json:"summary,omitempty"`
+}
+
+type WorktreeEntry struct {
+	Path           string `json:"path"`
+	Head           string `json:"head,omitempty"`
+	Branch         string `json:"branch,omitempty"`
+	Bare           bool   `json:"bare,omitempty"`
+	Detached       bool   `json:"detached,omitempty"`
+	Locked         bool   `json:"locked,omitempty"`
+	LockReason     string `json:"lock_reason,omitempty"`
+	Prunable       bool   `json:"prunable,omitempty"`
+	PrunableReason string `json:"prunable_reason,omitempty"`
+}
+
+type WorktreeResult struct {
+	Status string          `json:"status"`
+	Path   string          `json:"path,omitempty"`
+	Branch string          `json:"branch,omitempty"`
+	Head   string          `json:"head,omitempty"`
+	Pruned []WorktreeEntry `json:"pruned,omitempty"`
+}
+
+type DiffHunk struct {
+	ID       string   `json:"id"`
+	Header   string   `json:"header"`
+	OldStart int      `json:"old_start"`
+	OldLines int      `json:"old_lines"`
+	NewStart int      `json:"new_start"`
+	NewLines int      `json:"new_lines"`
+	Lines    []string `json:"lines"`
+}
+
+type HunkStageResult struct {
+	Status    string     `json:"status"`
+	Path      string     `json:"path"`
+	Applied   []string   `json:"applied,omitempty"`
+	Hunks     []DiffHunk `json:"hunks"`
+	Remaining *DiffStat  `json:"remaining,omitempty"`
+}
+
+type PatchLine struct {
+	Kind      string `json:"kind"`
+	OldLine   int    `json:"old_line,omitempty"`
+	NewLine   int    `json:"new_line,omitempty"`
+	Content   string `json:"content"`
+	NoNewline bool   `json:"no_newline,omitempty"`
+}
+
+type PatchHunk struct {
+	ID       string      `json:"id"`
+	Header   string      `json:"header"`
+	OldStart int         `json:"old_start"`
+	OldLines int         `json:"old_lines"`
+	NewStart int         `json:"new_start"`
+	NewLines int         `json:"new_lines"`
+	Section  string      `json:"section,omitempty"`
+	Lines    []PatchLine `json:"lines"`
+}
+
+type PatchFile struct {
+	Path       string      `json:"path"`
+	OldPath    string      `json:"old_path,omitempty"`
+	Status     string      `json:"status"`
+	Similarity int         `json:"similarity,omitempty"`
+	OldMode    string      `json:"old_mode,omitempty"`
+	NewMode    string      `json:"new_mode,omitempty"`
+	Binary     bool        `json:"binary,omitempty"`
+	Hunks      []PatchHunk `json:"hunks"`
+}
+
+type FileTruncation struct {
+	Path       string   `json:"path"`
+	Status     string   `json:"status"`
+	ShownLines int      `json:"shown_lines"`
+	TotalLines int      `json:"total_lines"`
+	Stat       DiffStat `json:"stat"`
+}
+
+type ErrorResult struct {
+	Error    string    `json:"error"`
+	Kind     ErrorKind `json:"kind"`
+	ExitCode int       `json:"exit_code,omitempty"`
+	Args     []string  `json:"args,omitempty"`
+	Stderr   string    `json:"stderr,omitempty"`
+	Lock     *LockInfo `json:"lock,omitempty"`
 }
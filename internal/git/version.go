@@ -0,0 +1,93 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// MinVersion is the oldest git grit supports; commit --trailer needs 2.32.
+const MinVersion = "2.32.0"
+
+// CheckVersion verifies that git is on PATH and at least MinVersion.
+func CheckVersion(ctx context.Context) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git not found on PATH")
+	}
+
+	out, err := Run(ctx, "", "version")
+	if err != nil {
+		return err
+	}
+
+	version := ParseVersion(out)
+	if version == "" {
+		return fmt.Errorf("unrecognized git version output %q", strings.TrimSpace(out))
+	}
+
+	if CompareVersions(version, MinVersion) < 0 {
+		return fmt.Errorf("git %s is older than the minimum supported version %s", version, MinVersion)
+	}
+
+	return nil
+}
+
+// ParseVersion extracts the version number from `git version` output, such
+// as "2.43.0" from "git version 2.43.0" or "2.39.3" from
+// "git version 2.39.3 (Apple Git-145)". Returns "" if there is none.
+func ParseVersion(out string) string {
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return ""
+	}
+
+	return fields[2]
+}
+
+// CompareVersions compares two dotted version numbers, returning -1, 0, or
+// 1. Non-numeric suffixes such as ".windows.1" or "-rc0" are ignored.
+func CompareVersions(a, b string) int {
+	as, bs := versionParts(a), versionParts(b)
+
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func versionParts(v string) []int {
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		end := 0
+		for end < len(field) && field[end] >= '0' && field[end] <= '9' {
+			end++
+		}
+
+		n, err := strconv.Atoi(field[:end])
+		if err != nil {
+			break
+		}
+
+		parts = append(parts, n)
+		if end < len(field) {
+			break
+		}
+	}
+	return parts
+}
@@ -0,0 +1,52 @@
+package git
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"git version 2.43.0\n", "2.43.0"},
+		{"git version 2.39.3 (Apple Git-145)\n", "2.39.3"},
+		{"git version 2.45.1.windows.1\n", "2.45.1.windows.1"},
+		{"", ""},
+		{"hub version 2.14.2\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ParseVersion(tt.input); got != tt.want {
+			t.Errorf("ParseVersion(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.43.0", "2.32.0", 1},
+		{"2.32.0", "2.32.0", 0},
+		{"2.31.9", "2.32.0", -1},
+		{"2.32", "2.32.0", 0},
+		{"2.45.1.windows.1", "2.45.1", 0},
+		{"2.44.0-rc0", "2.44.0", 0},
+		{"10.0.0", "9.99.99", 1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckVersionMissingGit(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	err := CheckVersion(t.Context())
+	if err == nil || err.Error() != "git not found on PATH" {
+		t.Errorf("CheckVersion() = %v, want git not found on PATH", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package metrics records tool and git subprocess activity and renders it
+// in the Prometheus text exposition format.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/protocol"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/server"
+)
+
+// latencyBuckets are the histogram upper bounds in seconds.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Default is the registry git.Run and the tool dispatcher record into.
+var Default = NewRegistry()
+
+// Registry holds counters and latency histograms keyed by tool name and
+// git subcommand.
+type Registry struct {
+	tools *family
+	git   *family
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		tools: newFamily(),
+		git:   newFamily(),
+	}
+}
+
+// ObserveTool records one call of the named tool. failed is true when the
+// call returned an error or an error result.
+func (r *Registry) ObserveTool(name string, d time.Duration, failed bool) {
+	r.tools.observe(name, d, failed)
+}
+
+// ObserveGit records one git subprocess run with the given subcommand.
+func (r *Registry) ObserveGit(subcommand string, d time.Duration, failed bool) {
+	r.git.observe(subcommand, d, failed)
+}
+
+// WriteText writes every metric in the Prometheus text format, along with
+// the number of active transport sessions.
+func (r *Registry) WriteText(w io.Writer, activeSessions int) error {
+	var b strings.Builder
+
+	r.tools.write(&b, "grit_tool_calls_total", "grit_tool_errors_total", "grit_tool_duration_seconds", "tool", "tool calls")
+	r.git.write(&b, "grit_git_commands_total", "grit_git_command_errors_total", "grit_git_command_duration_seconds", "subcommand", "git subprocesses")
+
+	fmt.Fprintf(&b, "# HELP grit_active_sessions Number of connected transport sessions.\n")
+	fmt.Fprintf(&b, "# TYPE grit_active_sessions gauge\n")
+	fmt.Fprintf(&b, "grit_active_sessions %d\n", activeSessions)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// unknownTool labels calls to tools the provider doesn't list, so clients
+// sending made-up names can't create a series per name.
+const unknownTool = "unknown"
+
+// InstrumentTools wraps a tool provider so every call is recorded in r.
+// Calls are labelled by tool name only for the tools the provider lists
+// now; the rest are counted together as "unknown".
+func (r *Registry) InstrumentTools(tools server.ToolProvider) server.ToolProvider {
+	known := make(map[string]bool)
+	if list, err := tools.ListTools(context.Background()); err == nil {
+		for _, tool := range list {
+			known[tool.Name] = true
+		}
+	}
+
+	return &instrumentedTools{ToolProvider: tools, registry: r, known: known}
+}
+
+type instrumentedTools struct {
+	server.ToolProvider
+	registry *Registry
+	known    map[string]bool
+}
+
+func (t *instrumentedTools) CallTool(ctx context.Context, name string, args json.RawMessage) (*protocol.ToolCallResult, error) {
+	start := time.Now()
+	result, err := t.ToolProvider.CallTool(ctx, name, args)
+
+	label := name
+	if !t.known[name] {
+		label = unknownTool
+	}
+	t.registry.ObserveTool(label, time.Since(start), err != nil || (result != nil && result.IsError))
+
+	return result, err
+}
+
+// family is a set of call counters, error counters, and latency histograms
+// sharing one label.
+type family struct {
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	calls   uint64
+	errors  uint64
+	buckets []uint64
+	sum     float64
+}
+
+func newFamily() *family {
+	return &family{series: make(map[string]*series)}
+}
+
+func (f *family) observe(label string, d time.Duration, failed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s := f.series[label]
+	if s == nil {
+		s = &series{buckets: make([]uint64, len(latencyBuckets))}
+		f.series[label] = s
+	}
+
+	seconds := d.Seconds()
+	s.calls++
+	s.sum += seconds
+	if failed {
+		s.errors++
+	}
+
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			s.buckets[i]++
+		}
+	}
+}
+
+func (f *family) write(b *strings.Builder, callsName, errorsName, durationName, labelName, what string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	labels := make([]string, 0, len(f.series))
+	for label := range f.series {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	fmt.Fprintf(b, "# HELP %s Number of %s.\n", callsName, what)
+	fmt.Fprintf(b, "# TYPE %s counter\n", callsName)
+	for _, label := range labels {
+		fmt.Fprintf(b, "%s{%s=%s} %d\n", callsName, labelName, quoteLabel(label), f.series[label].calls)
+	}
+
+	fmt.Fprintf(b, "# HELP %s Number of %s that failed.\n", errorsName, what)
+	fmt.Fprintf(b, "# TYPE %s counter\n", errorsName)
+	for _, label := range labels {
+		fmt.Fprintf(b, "%s{%s=%s} %d\n", errorsName, labelName, quoteLabel(label), f.series[label].errors)
+	}
+
+	fmt.Fprintf(b, "# HELP %s Duration of %s in seconds.\n", durationName, what)
+	fmt.Fprintf(b, "# TYPE %s histogram\n", durationName)
+	for _, label := range labels {
+		s := f.series[label]
+		quoted := quoteLabel(label)
+
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(b, "%s_bucket{%s=%s,le=\"%s\"} %d\n", durationName, labelName, quoted, formatFloat(bound), s.buckets[i])
+		}
+		fmt.Fprintf(b, "%s_bucket{%s=%s,le=\"+Inf\"} %d\n", durationName, labelName, quoted, s.calls)
+		fmt.Fprintf(b, "%s_sum{%s=%s} %s\n", durationName, labelName, quoted, formatFloat(s.sum))
+		fmt.Fprintf(b, "%s_count{%s=%s} %d\n", durationName, labelName, quoted, s.calls)
+	}
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes, and
+// newlines as the text format requires.
+func quoteLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/protocol"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	r.ObserveTool("status", 20*time.Millisecond, false)
+	r.ObserveTool("status", 2*time.Second, true)
+	r.ObserveGit("log", 3*time.Millisecond, false)
+
+	var b strings.Builder
+	if err := r.WriteText(&b, 3); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	text := b.String()
+
+	for _, want := range []string{
+		"# TYPE grit_tool_calls_total counter\n",
+		`grit_tool_calls_total{tool="status"} 2` + "\n",
+		`grit_tool_errors_total{tool="status"} 1` + "\n",
+		"# TYPE grit_tool_duration_seconds histogram\n",
+		`grit_tool_duration_seconds_bucket{tool="status",le="0.01"} 0` + "\n",
+		`grit_tool_duration_seconds_bucket{tool="status",le="0.025"} 1` + "\n",
+		`grit_tool_duration_seconds_bucket{tool="status",le="2.5"} 2` + "\n",
+		`grit_tool_duration_seconds_bucket{tool="status",le="+Inf"} 2` + "\n",
+		`grit_tool_duration_seconds_sum{tool="status"} 2.02` + "\n",
+		`grit_tool_duration_seconds_count{tool="status"} 2` + "\n",
+		`grit_git_commands_total{subcommand="log"} 1` + "\n",
+		`grit_git_command_errors_total{subcommand="log"} 0` + "\n",
+		`grit_git_command_duration_seconds_bucket{subcommand="log",le="0.005"} 1` + "\n",
+		"# TYPE grit_active_sessions gauge\n",
+		"grit_active_sessions 3\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output missing %q\n%s", want, text)
+		}
+	}
+}
+
+func TestWriteTextSortsLabels(t *testing.T) {
+	r := NewRegistry()
+	r.ObserveGit("status", time.Millisecond, false)
+	r.ObserveGit("diff", time.Millisecond, false)
+
+	var b strings.Builder
+	r.WriteText(&b, 0)
+	text := b.String()
+
+	diff := strings.Index(text, `grit_git_commands_total{subcommand="diff"}`)
+	status := strings.Index(text, `grit_git_commands_total{subcommand="status"}`)
+	if diff < 0 || status < 0 || diff > status {
+		t.Errorf("expected diff before status\n%s", text)
+	}
+}
+
+func TestQuoteLabel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"status", `"status"`},
+		{`a"b`, `"a\"b"`},
+		{`a\b`, `"a\\b"`},
+		{"a\nb", `"a\nb"`},
+	}
+
+	for _, tt := range tests {
+		if got := quoteLabel(tt.input); got != tt.want {
+			t.Errorf("quoteLabel(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+type fakeTools struct {
+	result *protocol.ToolCallResult
+	err    error
+}
+
+func (f fakeTools) ListTools(ctx context.Context) ([]protocol.Tool, error) {
+	return []protocol.Tool{{Name: "status"}}, nil
+}
+
+func (f fakeTools) CallTool(ctx context.Context, name string, args json.RawMessage) (*protocol.ToolCallResult, error) {
+	return f.result, f.err
+}
+
+func TestInstrumentTools(t *testing.T) {
+	r := NewRegistry()
+
+	ok := r.InstrumentTools(fakeTools{result: &protocol.ToolCallResult{}})
+	failed := r.InstrumentTools(fakeTools{result: protocol.ErrorResult("boom")})
+	broken := r.InstrumentTools(fakeTools{err: errors.New("boom")})
+
+	ok.CallTool(context.Background(), "status", nil)
+	failed.CallTool(context.Background(), "status", nil)
+	broken.CallTool(context.Background(), "status", nil)
+
+	tools, err := ok.ListTools(context.Background())
+	if err != nil || len(tools) != 1 {
+		t.Fatalf("ListTools = %v, %v; want the wrapped provider's tools", tools, err)
+	}
+
+	s := r.tools.series["status"]
+	if s.calls != 3 {
+		t.Errorf("calls = %d, want 3", s.calls)
+	}
+	if s.errors != 2 {
+		t.Errorf("errors = %d, want 2", s.errors)
+	}
+}
+
+func TestInstrumentToolsUnknownNames(t *testing.T) {
+	r := NewRegistry()
+	tools := r.InstrumentTools(fakeTools{err: errors.New("unknown tool")})
+
+	tools.CallTool(context.Background(), "no-such-tool", nil)
+	tools.CallTool(context.Background(), "another\nbogus", nil)
+
+	if len(r.tools.series) != 1 {
+		t.Fatalf("series = %v, want only %q", r.tools.series, unknownTool)
+	}
+	if s := r.tools.series[unknownTool]; s == nil || s.calls != 2 || s.errors != 2 {
+		t.Errorf("unknown series = %+v, want 2 failed calls", s)
+	}
+}
@@ -17,6 +17,8 @@ func registerBranchCommands(app *command.App) {
 			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
 			{Name: "remote", Type: command.Bool, Description: "List remote-tracking branches"},
 			{Name: "all", Type: command.Bool, Description: "List both local and remote-tracking branches"},
+			{Name: "limit", Type: command.Int, Description: "Maximum number of branches per page (default 100)"},
+			{Name: "cursor", Type: command.String, Description: "next_cursor from a previous call, to fetch the following page; pass the same other arguments"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git branch"}, UseWhen: "listing branches"},
@@ -55,12 +57,19 @@ func handleGitBranchList(ctx context.Context, args json.RawMessage, _ command.Pr
 		RepoPath string `json:"repo_path"`
 		Remote   bool   `json:"remote"`
 		All      bool   `json:"all"`
+		Limit    int    `json:"limit"`
+		Cursor   string `json:"cursor"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
+	cur, err := decodeCursor("branch_list", params.Cursor)
+	if err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid cursor: %v", err)), nil
+	}
+
 	gitArgs := []string{
 		"branch",
 		"--format=%(HEAD)\x1f%(refname:short)\x1f%(objectname:short)\x1f%(subject)\x1f%(upstream:short)\x1f%(upstream:track)\x1e",
@@ -74,12 +83,15 @@ func handleGitBranchList(ctx context.Context, args json.RawMessage, _ command.Pr
 
 	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git branch: %v", err)), nil
+		return gitErrorResult("git branch", err), nil
 	}
 
-	branches := git.ParseBranchList(out)
+	var result git.BranchListResult
+	result.Branches, result.NextCursor = pageAfter(git.ParseBranchList(out), cur, pageSize(params.Limit), func(b git.BranchEntry) string {
+		return b.Name
+	})
 
-	return command.JSONResult(branches), nil
+	return command.JSONResult(result), nil
 }
 
 func handleGitBranchCreate(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
@@ -100,7 +112,7 @@ func handleGitBranchCreate(ctx context.Context, args json.RawMessage, _ command.
 	}
 
 	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git branch create: %v", err)), nil
+		return gitErrorResult("git branch create", err), nil
 	}
 
 	return command.JSONResult(git.MutationResult{
@@ -130,7 +142,7 @@ func handleGitCheckout(ctx context.Context, args json.RawMessage, _ command.Prom
 	gitArgs = append(gitArgs, params.Ref)
 
 	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git checkout: %v", err)), nil
+		return gitErrorResult("git checkout", err), nil
 	}
 
 	return command.JSONResult(git.MutationResult{
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+func TestBranchListArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantFlag string
+	}{
+		{"local", `{"repo_path":"/repo"}`, ""},
+		{"remote", `{"repo_path":"/repo","remote":true}`, "-r"},
+		{"all", `{"repo_path":"/repo","all":true,"remote":true}`, "-a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("branch").Return("*\x1fmain\x1fabc1234\x1finitial\x1f\x1f\x1e")
+
+			var result git.BranchListResult
+			decodeResult(t, runTool(t, runner, "branch_list", tt.args), &result)
+
+			branches := result.Branches
+			if len(branches) != 1 || branches[0].Name != "main" || !branches[0].IsCurrent {
+				t.Errorf("branches = %+v, want current main", branches)
+			}
+
+			args := runner.Calls()[0].Args
+			switch {
+			case tt.wantFlag == "" && (slices.Contains(args, "-r") || slices.Contains(args, "-a")):
+				t.Errorf("args = %v, want no -r or -a", args)
+			case tt.wantFlag != "" && args[len(args)-1] != tt.wantFlag:
+				t.Errorf("args = %v, want trailing %s", args, tt.wantFlag)
+			}
+		})
+	}
+}
+
+func TestBranchCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantArgs []string
+	}{
+		{"from HEAD", `{"repo_path":"/repo","name":"feature"}`, []string{"branch", "feature"}},
+		{"from start point", `{"repo_path":"/repo","name":"feature","start_point":"v1.0"}`, []string{"branch", "feature", "v1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("branch")
+
+			var result git.MutationResult
+			decodeResult(t, runTool(t, runner, "branch_create", tt.args), &result)
+
+			if result.Status != "created" || result.Name != "feature" {
+				t.Errorf("result = %+v, want created feature", result)
+			}
+
+			if got := runner.Calls()[0].Args; !slices.Equal(got, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", got, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		fail     string
+		wantArgs []string
+		wantErr  string
+	}{
+		{
+			name:     "existing branch",
+			args:     `{"repo_path":"/repo","ref":"main"}`,
+			wantArgs: []string{"checkout", "main"},
+		},
+		{
+			name:     "create",
+			args:     `{"repo_path":"/repo","ref":"feature","create":true}`,
+			wantArgs: []string{"checkout", "-b", "feature"},
+		},
+		{
+			name:     "git error",
+			args:     `{"repo_path":"/repo","ref":"nope"}`,
+			fail:     "pathspec 'nope' did not match",
+			wantArgs: []string{"checkout", "nope"},
+			wantErr:  "git checkout: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			response := runner.On("checkout")
+			if tt.fail != "" {
+				response.Fail(tt.fail)
+			}
+
+			result := runTool(t, runner, "checkout", tt.args)
+
+			if got := runner.Calls()[0].Args; !slices.Equal(got, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", got, tt.wantArgs)
+			}
+
+			if tt.wantErr != "" {
+				if !result.IsErr || !strings.HasPrefix(result.Text, tt.wantErr) || !strings.Contains(result.Text, tt.fail) {
+					t.Errorf("result = %+v, want error %q", result, tt.wantErr)
+				}
+				return
+			}
+
+			var mutation git.MutationResult
+			decodeResult(t, result, &mutation)
+			if mutation.Status != "switched" {
+				t.Errorf("status = %q, want switched", mutation.Status)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
 	"github.com/friedenberg/grit/internal/git"
@@ -12,10 +13,18 @@ import (
 func registerCommitCommands(app *command.App) {
 	app.AddCommand(&command.Command{
 		Name:        "commit",
-		Description: command.Description{Short: "Create a new commit with staged changes"},
+		Description: command.Description{Short: "Create a new commit with staged changes, amend HEAD, or record a fixup/squash commit"},
 		Params: []command.Param{
 			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
-			{Name: "message", Type: command.String, Description: "Commit message", Required: true},
+			{Name: "message", Type: command.String, Description: "Commit message (required unless amending or creating a fixup/squash commit)"},
+			{Name: "amend", Type: command.Bool, Description: "Replace HEAD with a new commit; keeps the existing message unless message is given (--amend)"},
+			{Name: "allow_pushed", Type: command.Bool, Description: "Allow amending a commit that is already on the branch's upstream"},
+			{Name: "fixup", Type: command.String, Description: "Create a fixup! commit for this commit, to be folded in by an autosquash rebase (--fixup)"},
+			{Name: "squash", Type: command.String, Description: "Create a squash! commit for this commit, to be folded in by an autosquash rebase (--squash)"},
+			{Name: "allow_empty", Type: command.Bool, Description: "Allow a commit with no changes (--allow-empty)"},
+			{Name: "author", Type: command.String, Description: "Override the commit author (e.g. 'Jane Doe <jane@example.com>')"},
+			{Name: "trailers", Type: command.Array, Description: "Trailers to add to the message (e.g. ['Signed-off-by: Jane Doe <jane@example.com>', 'Co-authored-by: ...'])"},
+			{Name: "paths", Type: command.Array, Description: "Commit only these paths, ignoring other staged changes (relative to repo root)"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git commit"}, UseWhen: "creating a new commit"},
@@ -26,20 +35,134 @@ func registerCommitCommands(app *command.App) {
 
 func handleGitCommit(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
 	var params struct {
-		RepoPath string `json:"repo_path"`
-		Message  string `json:"message"`
+		RepoPath    string   `json:"repo_path"`
+		Message     string   `json:"message"`
+		Amend       bool     `json:"amend"`
+		AllowPushed bool     `json:"allow_pushed"`
+		Fixup       string   `json:"fixup"`
+		Squash      string   `json:"squash"`
+		AllowEmpty  bool     `json:"allow_empty"`
+		Author      string   `json:"author"`
+		Trailers    []string `json:"trailers"`
+		Paths       []string `json:"paths"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
-	out, err := git.Run(ctx, params.RepoPath, "commit", "-m", params.Message)
+	// Validate mutually exclusive modes
+	modeCount := 0
+	if params.Amend {
+		modeCount++
+	}
+	if params.Fixup != "" {
+		modeCount++
+	}
+	if params.Squash != "" {
+		modeCount++
+	}
+
+	if modeCount > 1 {
+		return command.TextErrorResult("only one of amend, fixup, or squash can be specified"), nil
+	}
+
+	if modeCount == 0 && params.Message == "" {
+		return command.TextErrorResult("message is required unless amend, fixup, or squash is specified"), nil
+	}
+
+	// Safety: rewriting a commit that is already on the upstream forces
+	// everyone else to reconcile with the rewrite
+	if params.Amend && !params.AllowPushed {
+		if upstream, pushed := headOnUpstream(ctx, params.RepoPath); pushed {
+			return command.TextErrorResult(fmt.Sprintf("HEAD is already pushed to %s; set allow_pushed to amend it anyway", upstream)), nil
+		}
+	}
+
+	gitArgs := []string{"commit"}
+
+	switch {
+	case params.Amend:
+		gitArgs = append(gitArgs, "--amend")
+		if params.Message == "" {
+			gitArgs = append(gitArgs, "--no-edit")
+		}
+	case params.Fixup != "":
+		gitArgs = append(gitArgs, "--fixup="+params.Fixup)
+	case params.Squash != "":
+		gitArgs = append(gitArgs, "--squash="+params.Squash)
+	}
+
+	if params.Message != "" {
+		gitArgs = append(gitArgs, "-m", params.Message)
+	}
+
+	if params.AllowEmpty {
+		gitArgs = append(gitArgs, "--allow-empty")
+	}
+
+	if params.Author != "" {
+		gitArgs = append(gitArgs, "--author="+params.Author)
+	}
+
+	for _, trailer := range params.Trailers {
+		gitArgs = append(gitArgs, "--trailer", trailer)
+	}
+
+	if len(params.Paths) > 0 {
+		gitArgs = append(gitArgs, "--")
+		gitArgs = append(gitArgs, params.Paths...)
+	}
+
+	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git commit: %v", err)), nil
+		return gitErrorResult("git commit", err), nil
 	}
 
 	result := git.ParseCommit(out)
 
+	if params.Amend {
+		result.Status = "amended"
+	}
+
+	if hash := resolveHead(ctx, params.RepoPath); hash != "" {
+		result.Hash = hash
+	}
+
+	subjectOut, err := git.Run(ctx, params.RepoPath, "log", "-1", "--format=%s", "HEAD")
+	if err == nil {
+		result.Subject = strings.TrimSpace(subjectOut)
+	}
+
+	numstatOut, err := git.Run(ctx, params.RepoPath, "show", "--numstat", "--format=", "HEAD")
+	if err != nil {
+		numstatOut = ""
+	}
+
+	result.Stats = git.ParseDiffNumstat(numstatOut)
+	result.Summary.TotalFiles = len(result.Stats)
+	for _, s := range result.Stats {
+		result.Summary.TotalAdditions += s.Additions
+		result.Summary.TotalDeletions += s.Deletions
+	}
+
 	return command.JSONResult(result), nil
 }
+
+// headOnUpstream reports whether HEAD is reachable from the current
+// branch's upstream, returning the upstream name. Branches without an
+// upstream are never considered pushed.
+func headOnUpstream(ctx context.Context, repoPath string) (string, bool) {
+	upstreamOut, err := git.Run(ctx, repoPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", false
+	}
+
+	upstream := strings.TrimSpace(upstreamOut)
+
+	if _, err := git.Run(ctx, repoPath, "merge-base", "--is-ancestor", "HEAD", upstream); err != nil {
+		return upstream, false
+	}
+
+	return upstream, true
+}
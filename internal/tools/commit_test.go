@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+// scriptCommitResult answers the calls the commit tool makes after
+// committing to describe the new HEAD.
+func scriptCommitResult(runner *gittest.FakeRunner) {
+	runner.On("rev-parse", "HEAD").Return("0123456789abcdef0123456789abcdef01234567\n")
+	runner.On("log", "-1").Return("Add feature\n")
+	runner.On("show", "--numstat").Return("3\t1\tmain.go\n")
+}
+
+func TestCommitValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		wantErr string
+	}{
+		{"missing message", `{"repo_path":"/repo"}`, "message is required"},
+		{"amend and fixup", `{"repo_path":"/repo","amend":true,"fixup":"HEAD~1"}`, "only one of amend, fixup, or squash"},
+		{"fixup and squash", `{"repo_path":"/repo","fixup":"a","squash":"b"}`, "only one of amend, fixup, or squash"},
+		{"bad arguments", `{"repo_path":1}`, "invalid arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+
+			result := runTool(t, runner, "commit", tt.args)
+
+			if !result.IsErr || !strings.Contains(result.Text, tt.wantErr) {
+				t.Errorf("result = %+v, want error containing %q", result, tt.wantErr)
+			}
+
+			if calls := runner.Calls(); len(calls) != 0 {
+				t.Errorf("git ran %d times, want 0", len(calls))
+			}
+		})
+	}
+}
+
+func TestCommitArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       string
+		wantArgs   []string
+		wantStatus string
+	}{
+		{
+			name:       "message",
+			args:       `{"repo_path":"/repo","message":"Add feature"}`,
+			wantArgs:   []string{"commit", "-m", "Add feature"},
+			wantStatus: "committed",
+		},
+		{
+			name:       "fixup with paths",
+			args:       `{"repo_path":"/repo","fixup":"abc123","paths":["a.go","b.go"]}`,
+			wantArgs:   []string{"commit", "--fixup=abc123", "--", "a.go", "b.go"},
+			wantStatus: "committed",
+		},
+		{
+			name:       "trailers and author",
+			args:       `{"repo_path":"/repo","message":"m","author":"A <a@b>","trailers":["Signed-off-by: A <a@b>"],"allow_empty":true}`,
+			wantArgs:   []string{"commit", "-m", "m", "--allow-empty", "--author=A <a@b>", "--trailer", "Signed-off-by: A <a@b>"},
+			wantStatus: "committed",
+		},
+		{
+			name:       "amend without message",
+			args:       `{"repo_path":"/repo","amend":true,"allow_pushed":true}`,
+			wantArgs:   []string{"commit", "--amend", "--no-edit"},
+			wantStatus: "amended",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("commit").Return("[main 0123456] Add feature\n 1 file changed\n")
+			scriptCommitResult(runner)
+
+			var result git.CommitResult
+			decodeResult(t, runTool(t, runner, "commit", tt.args), &result)
+
+			commit := runner.Find("commit")
+			if commit == nil || !slices.Equal(commit.Args, tt.wantArgs) {
+				t.Errorf("commit args = %v, want %v", commit, tt.wantArgs)
+			}
+
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if result.Hash != "0123456789abcdef0123456789abcdef01234567" {
+				t.Errorf("Hash = %q, want the full hash", result.Hash)
+			}
+			if result.Branch != "main" {
+				t.Errorf("Branch = %q, want main", result.Branch)
+			}
+			if result.Summary.TotalAdditions != 3 || result.Summary.TotalDeletions != 1 {
+				t.Errorf("Summary = %+v, want +3 -1", result.Summary)
+			}
+		})
+	}
+}
+
+func TestCommitAmendPushed(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        string
+		pushed      bool
+		wantErr     bool
+		wantChecked bool
+	}{
+		{"pushed", `{"repo_path":"/repo","amend":true}`, true, true, true},
+		{"not pushed", `{"repo_path":"/repo","amend":true}`, false, false, true},
+		{"pushed but allowed", `{"repo_path":"/repo","amend":true,"allow_pushed":true}`, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("rev-parse", "--abbrev-ref").Return("origin/main\n")
+			ancestor := runner.On("merge-base", "--is-ancestor")
+			if !tt.pushed {
+				ancestor.Fail("")
+			}
+			runner.On("commit").Return("[main 0123456] Add feature\n")
+			scriptCommitResult(runner)
+
+			result := runTool(t, runner, "commit", tt.args)
+
+			if tt.wantErr {
+				if !result.IsErr || !strings.Contains(result.Text, "already pushed to origin/main") {
+					t.Errorf("result = %+v, want already pushed error", result)
+				}
+				if runner.Called("commit") {
+					t.Error("commit ran despite the pushed HEAD")
+				}
+				return
+			}
+
+			if result.IsErr {
+				t.Fatalf("unexpected error: %s", result.Text)
+			}
+
+			if got := runner.Called("merge-base"); got != tt.wantChecked {
+				t.Errorf("checked upstream = %v, want %v", got, tt.wantChecked)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// defaultPageSize is how many entries a listing returns when the caller
+// doesn't give a limit.
+const defaultPageSize = 100
+
+// cursor is where a paginated listing left off. Callers get it as an opaque
+// next_cursor string and pass it back unchanged, with the same arguments,
+// to fetch the following page.
+type cursor struct {
+	Tool string `json:"t"`
+
+	// Revs pins the revisions a walk started from to object names, so
+	// commits arriving between pages don't shift later ones.
+	Revs []string `json:"r,omitempty"`
+
+	// Skip counts the entries or lines already returned.
+	Skip int `json:"s,omitempty"`
+
+	// After names the last entry returned by a ref or stash listing, which
+	// the next page continues from even if entries were added before it.
+	After string `json:"a,omitempty"`
+}
+
+// pinnedRevPattern matches what rev-parse prints for a revision: an object
+// name, negated for the excluded side of a range.
+var pinnedRevPattern = regexp.MustCompile(`^\^?[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor passed back to tool. An empty string is the
+// first page.
+func decodeCursor(tool, s string) (cursor, error) {
+	c := cursor{Tool: tool}
+	if s == "" {
+		return c, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("malformed cursor")
+	}
+
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("malformed cursor")
+	}
+
+	if c.Tool != tool {
+		return c, fmt.Errorf("cursor is from %s, not %s", c.Tool, tool)
+	}
+
+	for _, rev := range c.Revs {
+		if !pinnedRevPattern.MatchString(rev) {
+			return c, fmt.Errorf("malformed cursor")
+		}
+	}
+
+	if c.Skip < 0 {
+		return c, fmt.Errorf("malformed cursor")
+	}
+
+	return c, nil
+}
+
+// pinRevisions resolves the revisions git log would walk from to object
+// names. A range such as main..feature comes back as the included tip and
+// the negated excluded one.
+func pinRevisions(ctx context.Context, repoPath string, all bool, ref string) ([]string, error) {
+	args := []string{"rev-parse"}
+
+	if all {
+		args = append(args, "--all", "HEAD")
+	}
+
+	if ref != "" {
+		args = append(args, "--end-of-options", ref)
+	} else if !all {
+		args = append(args, "HEAD")
+	}
+
+	out, err := git.Run(ctx, repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var revs []string
+	for _, line := range strings.Fields(out) {
+		// Older gits echo --end-of-options back rather than consuming it.
+		if line == "--end-of-options" {
+			continue
+		}
+		revs = append(revs, line)
+	}
+
+	return revs, nil
+}
+
+func pageSize(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	return limit
+}
+
+// pageAfter returns up to limit items following the cursor's position in
+// items, and the cursor for the page after it, which is empty on the last
+// page. The position is found by key so entries added earlier in the list
+// don't repeat. If that entry has since been deleted, the page starts
+// where it would have been had nothing else changed.
+func pageAfter[T any](items []T, c cursor, limit int, key func(T) string) ([]T, string) {
+	start := c.Skip
+
+	if c.After != "" {
+		start = max(c.Skip-1, 0)
+		for i, item := range items {
+			if key(item) == c.After {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	start = min(start, len(items))
+
+	end := min(start+limit, len(items))
+	page := items[start:end]
+
+	if end == len(items) {
+		return page, ""
+	}
+
+	return page, cursor{Tool: c.Tool, Skip: end, After: key(items[end-1])}.encode()
+}
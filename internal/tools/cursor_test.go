@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPageAfter(t *testing.T) {
+	key := func(s string) string { return s }
+	items := []string{"a", "b", "c", "d", "e"}
+
+	page, next := pageAfter(items, cursor{Tool: "tag_list"}, 2, key)
+	if !slices.Equal(page, []string{"a", "b"}) || next == "" {
+		t.Fatalf("first page = %v, %q, want [a b] and a next cursor", page, next)
+	}
+
+	c, err := decodeCursor("tag_list", next)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+
+	// An entry added before the cursor doesn't repeat "b" on the next page.
+	grown := []string{"a", "aa", "b", "c", "d", "e"}
+	page, next = pageAfter(grown, c, 2, key)
+	if !slices.Equal(page, []string{"c", "d"}) || next == "" {
+		t.Fatalf("second page = %v, %q, want [c d] and a next cursor", page, next)
+	}
+
+	c, _ = decodeCursor("tag_list", next)
+
+	// With "d" deleted, the page starts where "d" was.
+	page, next = pageAfter([]string{"a", "aa", "b", "c", "e"}, c, 2, key)
+	if !slices.Equal(page, []string{"e"}) || next != "" {
+		t.Errorf("after deletion = %v, %q, want [e] and no next cursor", page, next)
+	}
+
+	page, next = pageAfter(grown, c, 2, key)
+	if !slices.Equal(page, []string{"e"}) || next != "" {
+		t.Errorf("last page = %v, %q, want [e] and no next cursor", page, next)
+	}
+}
+
+func TestDecodeCursorRejectsOtherTools(t *testing.T) {
+	if _, err := decodeCursor("stash_list", cursor{Tool: "branch_list"}.encode()); err == nil {
+		t.Error("decodeCursor accepted a branch_list cursor for stash_list")
+	}
+}
@@ -3,22 +3,45 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
 	"github.com/friedenberg/grit/internal/git"
 )
 
+// defaultBlameLines is how many lines blame returns per page when the
+// caller doesn't give a limit.
+const defaultBlameLines = 500
+
 func registerLogCommands(app *command.App) {
 	app.AddCommand(&command.Command{
 		Name:        "log",
 		Description: command.Description{Short: "Show commit history as structured JSON"},
 		Params: []command.Param{
 			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
-			{Name: "max_count", Type: command.Int, Description: "Maximum number of commits to show (default 10)"},
-			{Name: "ref", Type: command.String, Description: "Starting ref (commit, branch, tag)"},
+			{Name: "max_count", Type: command.Int, Description: "Maximum number of commits per page (default 10)"},
+			{Name: "cursor", Type: command.String, Description: "next_cursor from a previous call, to fetch the following page; pass the same other arguments"},
+			{Name: "ref", Type: command.String, Description: "Starting ref or revision range (e.g. main, HEAD~5, main..feature, v1.0...v2.0)"},
 			{Name: "paths", Type: command.Array, Description: "Limit to commits affecting these paths"},
 			{Name: "all", Type: command.Bool, Description: "Show commits from all branches"},
+			{Name: "author", Type: command.String, Description: "Only commits whose author name or email matches this pattern"},
+			{Name: "committer", Type: command.String, Description: "Only commits whose committer name or email matches this pattern"},
+			{Name: "since", Type: command.String, Description: "Only commits after this date (e.g. '2024-01-31', '2 weeks ago')"},
+			{Name: "until", Type: command.String, Description: "Only commits before this date"},
+			{Name: "grep", Type: command.String, Description: "Only commits whose message matches this pattern"},
+			{Name: "invert_grep", Type: command.Bool, Description: "Only commits whose message does not match grep"},
+			{Name: "regex", Type: command.String, Description: "How author, committer, and grep patterns match: basic (default), extended, or fixed (plain text)"},
+			{Name: "ignore_case", Type: command.Bool, Description: "Match author, committer, and grep patterns case-insensitively"},
+			{Name: "pickaxe", Type: command.String, Description: "Only commits that change the number of occurrences of this string in a file (-S), e.g. to find where a function was added or removed"},
+			{Name: "diff_grep", Type: command.String, Description: "Only commits whose added or removed lines match this regex (-G)"},
+			{Name: "no_merges", Type: command.Bool, Description: "Leave out merge commits"},
+			{Name: "merges_only", Type: command.Bool, Description: "Show only merge commits"},
+			{Name: "first_parent", Type: command.Bool, Description: "Follow only the first parent of merge commits, e.g. to list what was merged into main"},
+			{Name: "follow", Type: command.Bool, Description: "Continue listing a file's history across renames; requires exactly one path"},
+			{Name: "stats", Type: command.Bool, Description: "Include per-file added and deleted line counts for each commit (--numstat)"},
+			{Name: "signatures", Type: command.Bool, Description: "Include each commit's GPG/SSH signature status; verifying signatures is slow on long histories"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git log"}, UseWhen: "viewing commit history"},
@@ -33,7 +56,10 @@ func registerLogCommands(app *command.App) {
 			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
 			{Name: "ref", Type: command.String, Description: "Ref to show (commit hash, tag, branch, etc.)", Required: true},
 			{Name: "context_lines", Type: command.Int, Description: "Number of context lines around each change (git --unified=N, default 3)"},
-			{Name: "max_patch_lines", Type: command.Int, Description: "Maximum number of patch output lines. Output is truncated with a truncated flag when exceeded."},
+			{Name: "max_patch_lines", Type: command.Int, Description: "Maximum number of patch output lines. Output is truncated with a truncated flag when exceeded. Structured output is cut at hunk boundaries."},
+			{Name: "format", Type: command.String, Description: "Patch format: patch (default, raw unified diff) or structured (files, hunks, and lines with line numbers)"},
+			{Name: "budget_lines", Type: command.Int, Description: "Patch line budget spread across files: small files are shown whole, large ones are cut at hunk boundaries or summarised, and every cut file is listed in truncated_files. Replaces max_patch_lines."},
+			{Name: "exclude", Type: command.Array, Description: "Glob patterns for files to summarise instead of showing their patch (e.g. ['*.lock', 'testdata/*']); patterns without a slash match the file name in any directory"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git show"}, UseWhen: "inspecting commits or objects"},
@@ -49,6 +75,8 @@ func registerLogCommands(app *command.App) {
 			{Name: "path", Type: command.String, Description: "File path to blame (relative to repo root)", Required: true},
 			{Name: "ref", Type: command.String, Description: "Blame at a specific ref"},
 			{Name: "line_range", Type: command.String, Description: "Line range in format START,END (e.g. '10,20')"},
+			{Name: "limit", Type: command.Int, Description: "Maximum number of lines per page (default 500)"},
+			{Name: "cursor", Type: command.String, Description: "next_cursor from a previous call, to fetch the following page; pass the same other arguments"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git blame"}, UseWhen: "viewing line-by-line authorship"},
@@ -59,34 +87,152 @@ func registerLogCommands(app *command.App) {
 
 func handleGitLog(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
 	var params struct {
-		RepoPath string   `json:"repo_path"`
-		MaxCount int      `json:"max_count"`
-		Ref      string   `json:"ref"`
-		Paths    []string `json:"paths"`
-		All      bool     `json:"all"`
+		RepoPath    string   `json:"repo_path"`
+		MaxCount    int      `json:"max_count"`
+		Ref         string   `json:"ref"`
+		Paths       []string `json:"paths"`
+		All         bool     `json:"all"`
+		Author      string   `json:"author"`
+		Committer   string   `json:"committer"`
+		Since       string   `json:"since"`
+		Until       string   `json:"until"`
+		Grep        string   `json:"grep"`
+		InvertGrep  bool     `json:"invert_grep"`
+		Regex       string   `json:"regex"`
+		IgnoreCase  bool     `json:"ignore_case"`
+		Pickaxe     string   `json:"pickaxe"`
+		DiffGrep    string   `json:"diff_grep"`
+		NoMerges    bool     `json:"no_merges"`
+		MergesOnly  bool     `json:"merges_only"`
+		FirstParent bool     `json:"first_parent"`
+		Follow      bool     `json:"follow"`
+		Stats       bool     `json:"stats"`
+		Signatures  bool     `json:"signatures"`
+		Cursor      string   `json:"cursor"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
+	if params.NoMerges && params.MergesOnly {
+		return command.TextErrorResult("no_merges and merges_only cannot both be specified"), nil
+	}
+
+	if params.Follow && len(params.Paths) != 1 {
+		return command.TextErrorResult("follow requires exactly one path"), nil
+	}
+
+	if params.InvertGrep && params.Grep == "" {
+		return command.TextErrorResult("invert_grep requires grep"), nil
+	}
+
+	if params.Pickaxe != "" && params.DiffGrep != "" {
+		return command.TextErrorResult("pickaxe and diff_grep cannot both be specified"), nil
+	}
+
+	cur, err := decodeCursor("log", params.Cursor)
+	if err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid cursor: %v", err)), nil
+	}
+
 	gitArgs := []string{"log"}
 
 	maxCount := params.MaxCount
 	if maxCount <= 0 {
 		maxCount = 10
 	}
-	gitArgs = append(gitArgs, fmt.Sprintf("--max-count=%d", maxCount))
-	gitArgs = append(gitArgs, fmt.Sprintf("--format=%s", git.LogFormat))
+	// One extra commit tells whether there is another page.
+	gitArgs = append(gitArgs, fmt.Sprintf("--max-count=%d", maxCount+1))
+
+	format := git.LogFormat
+	if params.Signatures {
+		format = git.SignedLogFormat
+	}
+	gitArgs = append(gitArgs, fmt.Sprintf("--format=%s", format), "--decorate=full")
+
+	if params.Stats {
+		gitArgs = append(gitArgs, "--numstat")
+	}
+
+	switch params.Regex {
+	case "", "basic":
+	case "extended":
+		gitArgs = append(gitArgs, "--extended-regexp")
+	case "fixed":
+		gitArgs = append(gitArgs, "--fixed-strings")
+	default:
+		return command.TextErrorResult(fmt.Sprintf("invalid regex %q: must be basic, extended, or fixed", params.Regex)), nil
+	}
+
+	if params.IgnoreCase {
+		gitArgs = append(gitArgs, "--regexp-ignore-case")
+	}
+
+	if params.Author != "" {
+		gitArgs = append(gitArgs, "--author="+params.Author)
+	}
+
+	if params.Committer != "" {
+		gitArgs = append(gitArgs, "--committer="+params.Committer)
+	}
+
+	if params.Since != "" {
+		gitArgs = append(gitArgs, "--since="+params.Since)
+	}
+
+	if params.Until != "" {
+		gitArgs = append(gitArgs, "--until="+params.Until)
+	}
+
+	if params.Grep != "" {
+		gitArgs = append(gitArgs, "--grep="+params.Grep)
+	}
+
+	if params.InvertGrep {
+		gitArgs = append(gitArgs, "--invert-grep")
+	}
+
+	if params.Pickaxe != "" {
+		gitArgs = append(gitArgs, "-S"+params.Pickaxe)
+	}
+
+	if params.DiffGrep != "" {
+		gitArgs = append(gitArgs, "-G"+params.DiffGrep)
+	}
+
+	if params.NoMerges {
+		gitArgs = append(gitArgs, "--no-merges")
+	}
+
+	if params.MergesOnly {
+		gitArgs = append(gitArgs, "--merges")
+	}
+
+	if params.FirstParent {
+		gitArgs = append(gitArgs, "--first-parent")
+	}
+
+	if params.Follow {
+		gitArgs = append(gitArgs, "--follow")
+	}
 
-	if params.All {
-		gitArgs = append(gitArgs, "--all")
+	if cur.Skip > 0 {
+		gitArgs = append(gitArgs, fmt.Sprintf("--skip=%d", cur.Skip))
 	}
 
-	if params.Ref != "" {
-		gitArgs = append(gitArgs, params.Ref)
+	// The first page pins where the walk starts, so later pages skip
+	// exactly the commits already returned.
+	if params.Cursor == "" {
+		cur.Revs, err = pinRevisions(ctx, params.RepoPath, params.All, params.Ref)
+		if err != nil {
+			return gitErrorResult("git rev-parse", err), nil
+		}
 	}
 
+	gitArgs = append(gitArgs, "--end-of-options")
+	gitArgs = append(gitArgs, cur.Revs...)
+
 	if len(params.Paths) > 0 {
 		gitArgs = append(gitArgs, "--")
 		gitArgs = append(gitArgs, params.Paths...)
@@ -94,32 +240,45 @@ func handleGitLog(ctx context.Context, args json.RawMessage, _ command.Prompter)
 
 	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git log: %v", err)), nil
+		return gitErrorResult("git log", err), nil
 	}
 
-	entries := git.ParseLog(out)
+	result := git.LogResult{Commits: git.ParseLog(out)}
 
-	return command.JSONResult(entries), nil
+	if len(result.Commits) > maxCount {
+		result.Commits = result.Commits[:maxCount]
+		cur.Skip += maxCount
+		result.NextCursor = cur.encode()
+	}
+
+	return command.JSONResult(result), nil
 }
 
 func handleGitShow(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
 	var params struct {
-		RepoPath      string `json:"repo_path"`
-		Ref           string `json:"ref"`
-		ContextLines  *int   `json:"context_lines"`
-		MaxPatchLines int    `json:"max_patch_lines"`
+		RepoPath      string   `json:"repo_path"`
+		Ref           string   `json:"ref"`
+		ContextLines  *int     `json:"context_lines"`
+		MaxPatchLines int      `json:"max_patch_lines"`
+		Format        string   `json:"format"`
+		BudgetLines   int      `json:"budget_lines"`
+		Exclude       []string `json:"exclude"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
+	if err := validatePatchOptions(params.Format, params.MaxPatchLines, params.BudgetLines); err != nil {
+		return command.TextErrorResult(err.Error()), nil
+	}
+
 	metadataOut, err := git.Run(ctx, params.RepoPath, "show", "--no-patch", fmt.Sprintf("--format=%s", git.ShowFormat), params.Ref)
 	if err != nil {
 		// Fall back to raw output for non-commit objects (tags, blobs)
 		out, fallbackErr := git.Run(ctx, params.RepoPath, "show", params.Ref)
 		if fallbackErr != nil {
-			return command.TextErrorResult(fmt.Sprintf("git show: %v", err)), nil
+			return gitErrorResult("git show", fallbackErr), nil
 		}
 		return command.TextResult(out), nil
 	}
@@ -142,10 +301,12 @@ func handleGitShow(ctx context.Context, args json.RawMessage, _ command.Prompter
 
 	result := git.ParseShow(metadataOut, numstatOut, patchOut)
 
-	patch, truncated, truncatedAt := git.TruncatePatch(result.Patch, params.MaxPatchLines)
-	result.Patch = patch
-	result.Truncated = truncated
-	result.TruncatedAtLine = truncatedAt
+	rendered := renderPatch(result.Patch, params.Format, params.MaxPatchLines, params.BudgetLines, params.Exclude)
+	result.Patch = rendered.patch
+	result.Files = rendered.files
+	result.Truncated = rendered.truncated
+	result.TruncatedAtLine = rendered.truncatedAtLine
+	result.TruncatedFiles = rendered.truncatedFiles
 
 	return command.JSONResult(result), nil
 }
@@ -156,30 +317,72 @@ func handleGitBlame(ctx context.Context, args json.RawMessage, _ command.Prompte
 		Path      string `json:"path"`
 		Ref       string `json:"ref"`
 		LineRange string `json:"line_range"`
+		Limit     int    `json:"limit"`
+		Cursor    string `json:"cursor"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
+	cur, err := decodeCursor("blame", params.Cursor)
+	if err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid cursor: %v", err)), nil
+	}
+
+	// Pin the ref so every page blames the same commit. Blaming the
+	// working tree can't be pinned.
+	if params.Cursor == "" && params.Ref != "" {
+		cur.Revs, err = pinRevisions(ctx, params.RepoPath, false, params.Ref)
+		if err != nil {
+			return gitErrorResult("git rev-parse", err), nil
+		}
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultBlameLines
+	}
+
 	gitArgs := []string{"blame", "--porcelain"}
 
+	// Without a line range, git blames just this page's lines plus one
+	// more to tell whether another page follows. A caller's range can take
+	// forms such as /regex/ or :funcname that can't be offset, so it is
+	// blamed whole and paged here.
 	if params.LineRange != "" {
 		gitArgs = append(gitArgs, fmt.Sprintf("-L%s", params.LineRange))
+	} else {
+		gitArgs = append(gitArgs, fmt.Sprintf("-L%d,+%d", cur.Skip+1, limit+1))
 	}
 
-	if params.Ref != "" {
-		gitArgs = append(gitArgs, params.Ref)
-	}
-
+	gitArgs = append(gitArgs, cur.Revs...)
 	gitArgs = append(gitArgs, "--", params.Path)
 
 	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
-	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git blame: %v", err)), nil
+	if err != nil && !blamedEmptyFile(err) {
+		return gitErrorResult("git blame", err), nil
 	}
 
 	lines := git.ParseBlame(out)
+	if params.LineRange != "" {
+		lines = lines[min(cur.Skip, len(lines)):]
+	}
+
+	result := git.BlameResult{Lines: lines}
+
+	if len(lines) > limit {
+		result.Lines = lines[:limit]
+		cur.Skip += limit
+		result.NextCursor = cur.encode()
+	}
+
+	return command.JSONResult(result), nil
+}
 
-	return command.JSONResult(lines), nil
+// blamedEmptyFile reports whether blame failed only because -L asked for a
+// line of a file that has none.
+func blamedEmptyFile(err error) bool {
+	var gitErr *git.Error
+	return errors.As(err, &gitErr) && strings.Contains(gitErr.Stderr, "has only 0 lines")
 }
@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+func TestLogFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantArgs []string
+		wantTail []string
+	}{
+		{
+			"author and dates",
+			`{"repo_path":"/repo","author":"alice","committer":"bob","since":"2 weeks ago","until":"2024-01-31"}`,
+			[]string{"--author=alice", "--committer=bob", "--since=2 weeks ago", "--until=2024-01-31"},
+			nil,
+		},
+		{
+			"grep",
+			`{"repo_path":"/repo","grep":"fix(es)?","regex":"extended","invert_grep":true,"ignore_case":true}`,
+			[]string{"--extended-regexp", "--regexp-ignore-case", "--grep=fix(es)?", "--invert-grep"},
+			nil,
+		},
+		{
+			"pickaxe",
+			`{"repo_path":"/repo","pickaxe":"handleGitLog"}`,
+			[]string{"-ShandleGitLog"},
+			nil,
+		},
+		{
+			"diff grep",
+			`{"repo_path":"/repo","diff_grep":"func \\w+"}`,
+			[]string{"-Gfunc \\w+"},
+			nil,
+		},
+		{
+			"merges",
+			`{"repo_path":"/repo","merges_only":true,"first_parent":true}`,
+			[]string{"--merges", "--first-parent"},
+			nil,
+		},
+		{
+			"range",
+			`{"repo_path":"/repo","ref":"main..feature","no_merges":true}`,
+			[]string{"--no-merges"},
+			[]string{"--end-of-options", featureHash, "^" + mainHash},
+		},
+		{
+			"follow",
+			`{"repo_path":"/repo","follow":true,"paths":["new.go"]}`,
+			[]string{"--follow"},
+			[]string{"--", "new.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("rev-parse", "--end-of-options", "main..feature").Return(featureHash + "\n^" + mainHash + "\n")
+			runner.On("rev-parse", "HEAD").Return(featureHash + "\n")
+			runner.On("log").Return(logRecord("abc123", "Fix it"))
+
+			var result git.LogResult
+			decodeResult(t, runTool(t, runner, "log", tt.args), &result)
+
+			if len(result.Commits) != 1 || result.Commits[0].Hash != "abc123" || result.NextCursor != "" {
+				t.Errorf("result = %+v, want one parsed entry and no next page", result)
+			}
+
+			args := runner.Find("log").Args
+			for _, want := range tt.wantArgs {
+				if !slices.Contains(args, want) {
+					t.Errorf("args = %v, want %q", args, want)
+				}
+			}
+			if !slices.Equal(args[len(args)-len(tt.wantTail):], tt.wantTail) {
+				t.Errorf("args = %v, want ending %v", args, tt.wantTail)
+			}
+		})
+	}
+}
+
+func TestLogInvalidFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+	}{
+		{"merges conflict", `{"repo_path":"/repo","no_merges":true,"merges_only":true}`},
+		{"follow without path", `{"repo_path":"/repo","follow":true}`},
+		{"follow with two paths", `{"repo_path":"/repo","follow":true,"paths":["a","b"]}`},
+		{"invert without grep", `{"repo_path":"/repo","invert_grep":true}`},
+		{"pickaxe and diff grep", `{"repo_path":"/repo","pickaxe":"a","diff_grep":"b"}`},
+		{"unknown regex", `{"repo_path":"/repo","grep":"x","regex":"perl"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			result := runTool(t, runner, "log", tt.args)
+
+			if !result.IsErr {
+				t.Errorf("result = %+v, want error", result)
+			}
+			if len(runner.Calls()) != 0 {
+				t.Errorf("git ran for invalid arguments: %v", runner.Calls())
+			}
+		})
+	}
+}
+
+const (
+	mainHash    = "1111111111111111111111111111111111111111"
+	featureHash = "2222222222222222222222222222222222222222"
+)
+
+// logRecord formats one commit the way git log prints git.LogFormat.
+func logRecord(hash, subject string) string {
+	return "\x1e" + hash + "\x1f\x1fAlice\x1falice@example.com\x1f2024-01-01T00:00:00Z\x1fAlice\x1falice@example.com\x1f2024-01-01T00:00:00Z\x1f\x1f\x1f\x1f\x1f\x1f" + subject + "\x1f\x1f\n"
+}
+
+func TestLogDetailOptions(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("rev-parse").Return(featureHash + "\n")
+	runner.On("log").Return(logRecord("abc123", "Fix it") + "\n3\t1\tmain.go\n")
+
+	var result git.LogResult
+	decodeResult(t, runTool(t, runner, "log", `{"repo_path":"/repo","stats":true,"signatures":true}`), &result)
+
+	entries := result.Commits
+	if len(entries) != 1 || len(entries[0].Stats) != 1 || entries[0].Stats[0].Additions != 3 {
+		t.Errorf("entries = %+v, want one entry with stats", entries)
+	}
+
+	args := runner.Find("log").Args
+	for _, want := range []string{"--format=" + git.SignedLogFormat, "--decorate=full", "--numstat"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("args = %v, want %q", args, want)
+		}
+	}
+}
+
+func TestLogPagination(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("rev-parse", "--end-of-options", "main..feature").Return(featureHash + "\n^" + mainHash + "\n")
+	runner.On("log").Return(logRecord("c3", "three") + logRecord("c2", "two") + logRecord("c1", "one")).Times(1)
+	runner.On("log").Return(logRecord("c1", "one"))
+
+	var first git.LogResult
+	decodeResult(t, runTool(t, runner, "log", `{"repo_path":"/repo","ref":"main..feature","max_count":2}`), &first)
+
+	if len(first.Commits) != 2 || first.Commits[1].Hash != "c2" || first.NextCursor == "" {
+		t.Fatalf("first page = %+v, want c3, c2 and a next cursor", first)
+	}
+
+	var second git.LogResult
+	decodeResult(t, runTool(t, runner, "log", `{"repo_path":"/repo","ref":"main..feature","max_count":2,"cursor":"`+first.NextCursor+`"}`), &second)
+
+	if len(second.Commits) != 1 || second.Commits[0].Hash != "c1" || second.NextCursor != "" {
+		t.Errorf("second page = %+v, want c1 and no next cursor", second)
+	}
+
+	calls := runner.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("calls = %v, want rev-parse and two logs", calls)
+	}
+
+	// The second page walks from the pinned hashes, not the ref, so
+	// commits pushed to feature in between don't shift it.
+	args := calls[2].Args
+	wantTail := []string{"--end-of-options", featureHash, "^" + mainHash}
+	if !slices.Contains(args, "--skip=2") || !slices.Contains(args, "--max-count=3") || !slices.Equal(args[len(args)-3:], wantTail) {
+		t.Errorf("second page args = %v, want --skip=2, --max-count=3 and pinned revisions", args)
+	}
+}
+
+func TestLogInvalidCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"garbage", "not a cursor"},
+		{"other tool", cursor{Tool: "blame", Skip: 10}.encode()},
+		{"option as revision", cursor{Tool: "log", Revs: []string{"--output=/tmp/x"}}.encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			result := runTool(t, runner, "log", `{"repo_path":"/repo","cursor":"`+tt.cursor+`"}`)
+
+			if !result.IsErr || !strings.HasPrefix(result.Text, "invalid cursor") {
+				t.Errorf("result = %+v, want invalid cursor error", result)
+			}
+			if len(runner.Calls()) != 0 {
+				t.Errorf("git ran for an invalid cursor: %v", runner.Calls())
+			}
+		})
+	}
+}
+
+func TestBlamePagination(t *testing.T) {
+	blameLine := func(n int) string {
+		return fmt.Sprintf("%s %d %d 1\nauthor A\nsummary s\n\tline %d\n", featureHash, n, n, n)
+	}
+
+	runner := gittest.NewFakeRunner(t)
+	runner.On("rev-parse", "--end-of-options", "main").Return(featureHash + "\n")
+	runner.On("blame", "--porcelain", "-L1,+3").Return(blameLine(1) + blameLine(2) + blameLine(3))
+	runner.On("blame", "--porcelain", "-L3,+3").Return(blameLine(3))
+
+	var first git.BlameResult
+	decodeResult(t, runTool(t, runner, "blame", `{"repo_path":"/repo","path":"a.go","ref":"main","limit":2}`), &first)
+
+	if len(first.Lines) != 2 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v, want two lines and a next cursor", first)
+	}
+
+	var second git.BlameResult
+	decodeResult(t, runTool(t, runner, "blame", `{"repo_path":"/repo","path":"a.go","ref":"main","limit":2,"cursor":"`+first.NextCursor+`"}`), &second)
+
+	if len(second.Lines) != 1 || second.Lines[0].FinalLine != 3 || second.NextCursor != "" {
+		t.Errorf("second page = %+v, want line 3 and no next cursor", second)
+	}
+
+	want := []string{"blame", "--porcelain", "-L3,+3", featureHash, "--", "a.go"}
+	if args := runner.Calls()[2].Args; !slices.Equal(args, want) {
+		t.Errorf("second page args = %v, want %v", args, want)
+	}
+}
+
+func TestBlameEmptyFile(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("blame").Fail("fatal: file empty.txt has only 0 lines\n")
+
+	var result git.BlameResult
+	decodeResult(t, runTool(t, runner, "blame", `{"repo_path":"/repo","path":"empty.txt"}`), &result)
+
+	if len(result.Lines) != 0 || result.NextCursor != "" {
+		t.Errorf("result = %+v, want no lines", result)
+	}
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+func registerMergeCommands(app *command.App) {
+	app.AddCommand(&command.Command{
+		Name:        "merge",
+		Description: command.Description{Short: "Merge a branch into the current branch (fast-forward only on main/master for safety)"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "ref", Type: command.String, Description: "Branch, tag, or commit to merge into the current branch"},
+			{Name: "ff_only", Type: command.Bool, Description: "Refuse to merge unless it can be fast-forwarded (--ff-only)"},
+			{Name: "no_ff", Type: command.Bool, Description: "Always create a merge commit, even when a fast-forward is possible (--no-ff)"},
+			{Name: "squash", Type: command.Bool, Description: "Stage the combined changes without committing or recording a merge (--squash)"},
+			{Name: "message", Type: command.String, Description: "Message for the merge commit"},
+			{Name: "continue", Type: command.Bool, Description: "Conclude a merge after resolving conflicts"},
+			{Name: "abort", Type: command.Bool, Description: "Abort the current merge and restore the pre-merge state"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git merge"}, UseWhen: "merging branches"},
+		},
+		Run: handleGitMerge,
+	})
+}
+
+func handleGitMerge(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Ref      string `json:"ref"`
+		FFOnly   bool   `json:"ff_only"`
+		NoFF     bool   `json:"no_ff"`
+		Squash   bool   `json:"squash"`
+		Message  string `json:"message"`
+		Continue bool   `json:"continue"`
+		Abort    bool   `json:"abort"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	// Validate mutually exclusive operations
+	opCount := 0
+	if params.Continue {
+		opCount++
+	}
+	if params.Abort {
+		opCount++
+	}
+	if params.Ref != "" {
+		opCount++
+	}
+
+	if opCount > 1 {
+		return command.TextErrorResult("only one of ref, continue, or abort can be specified"), nil
+	}
+
+	if opCount == 0 {
+		return command.TextErrorResult("must specify ref (for new merge) or continue/abort (for existing merge)"), nil
+	}
+
+	// Handle abort
+	if params.Abort {
+		if _, err := git.Run(ctx, params.RepoPath, "merge", "--abort"); err != nil {
+			return gitErrorResult("git merge --abort", err), nil
+		}
+
+		return command.JSONResult(git.MergeResult{
+			Status: "aborted",
+		}), nil
+	}
+
+	// Handle continue
+	if params.Continue {
+		out, err := git.Run(ctx, params.RepoPath, "merge", "--continue")
+		if err != nil {
+			if conflicts := extractConflictFiles(ctx, params.RepoPath); len(conflicts) > 0 {
+				return command.JSONResult(git.MergeResult{
+					Status:    "conflict",
+					Conflicts: conflicts,
+				}), nil
+			}
+			return gitErrorResult("git merge --continue", err), nil
+		}
+
+		return command.JSONResult(git.MergeResult{
+			Status:  "merged",
+			Hash:    resolveHead(ctx, params.RepoPath),
+			Summary: strings.TrimSpace(out),
+		}), nil
+	}
+
+	// Handle new merge
+	if params.FFOnly && params.NoFF {
+		return command.TextErrorResult("ff_only and no_ff cannot both be specified"), nil
+	}
+
+	if params.Squash && params.NoFF {
+		return command.TextErrorResult("squash and no_ff cannot both be specified"), nil
+	}
+
+	currentBranch := ""
+	branchOut, err := git.Run(ctx, params.RepoPath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err == nil {
+		currentBranch = strings.TrimSpace(branchOut)
+	}
+
+	// Safety: only fast-forward main/master so it never gains local-only
+	// merge commits
+	if (currentBranch == "main" || currentBranch == "master") && !params.FFOnly {
+		return command.TextErrorResult("merging into main/master is only allowed with ff_only for safety"), nil
+	}
+
+	// Check for existing merge state
+	if git.GitPathExists(ctx, params.RepoPath, "MERGE_HEAD") {
+		return command.TextErrorResult("a merge operation is already in progress; use continue or abort"), nil
+	}
+
+	gitArgs := []string{"merge"}
+
+	if params.FFOnly {
+		gitArgs = append(gitArgs, "--ff-only")
+	}
+
+	if params.NoFF {
+		gitArgs = append(gitArgs, "--no-ff")
+	}
+
+	if params.Squash {
+		gitArgs = append(gitArgs, "--squash")
+	}
+
+	if params.Message != "" {
+		gitArgs = append(gitArgs, "-m", params.Message)
+	}
+
+	gitArgs = append(gitArgs, params.Ref)
+
+	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
+	if err != nil {
+		if conflicts := extractConflictFiles(ctx, params.RepoPath); len(conflicts) > 0 {
+			return command.JSONResult(git.MergeResult{
+				Status:    "conflict",
+				Branch:    currentBranch,
+				Ref:       params.Ref,
+				Conflicts: conflicts,
+			}), nil
+		}
+		return gitErrorResult("git merge", err), nil
+	}
+
+	result := git.MergeResult{
+		Status:  "merged",
+		Branch:  currentBranch,
+		Ref:     params.Ref,
+		Hash:    resolveHead(ctx, params.RepoPath),
+		Summary: strings.TrimSpace(out),
+	}
+
+	switch {
+	case strings.Contains(out, "Already up to date"):
+		result.Status = "up_to_date"
+		result.Summary = ""
+	case params.Squash:
+		result.Status = "squashed"
+	case strings.Contains(out, "Fast-forward"):
+		result.Status = "fast_forward"
+	}
+
+	return command.JSONResult(result), nil
+}
+
+func resolveHead(ctx context.Context, repoPath string) string {
+	out, err := git.Run(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(out)
+}
@@ -15,12 +15,14 @@ import (
 func registerRebaseCommands(app *command.App) {
 	app.AddCommand(&command.Command{
 		Name:        "rebase",
-		Description: command.Description{Short: "Rebase current branch onto another ref (blocked on main/master for safety)"},
+		Description: command.Description{Short: "Rebase current branch onto another ref, optionally following a todo plan (blocked on main/master for safety)"},
 		Params: []command.Param{
 			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
 			{Name: "upstream", Type: command.String, Description: "Ref to rebase onto (branch, tag, commit)"},
 			{Name: "branch", Type: command.String, Description: "Branch to rebase (defaults to current branch)"},
 			{Name: "autostash", Type: command.Bool, Description: "Automatically stash/unstash uncommitted changes"},
+			{Name: "rebase_plan", Type: command.Array, Description: "Interactive rebase todo, one step per commit in upstream..branch, oldest first. Each step is '<action> <commit> [message]' or an {action, commit, message} object; actions are pick, reword, squash, fixup, and drop. A message is required for reword and replaces the resulting message for squash and fixup."},
+			{Name: "autosquash", Type: command.Bool, Description: "Fold fixup!/squash! commits into their targets (--autosquash); cannot be combined with rebase_plan"},
 			{Name: "continue", Type: command.Bool, Description: "Continue rebase after resolving conflicts"},
 			{Name: "abort", Type: command.Bool, Description: "Abort current rebase operation"},
 			{Name: "skip", Type: command.Bool, Description: "Skip current commit and continue rebase"},
@@ -34,19 +36,25 @@ func registerRebaseCommands(app *command.App) {
 
 func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
 	var params struct {
-		RepoPath  string `json:"repo_path"`
-		Upstream  string `json:"upstream"`
-		Branch    string `json:"branch"`
-		Autostash bool   `json:"autostash"`
-		Continue  bool   `json:"continue"`
-		Abort     bool   `json:"abort"`
-		Skip      bool   `json:"skip"`
+		RepoPath   string           `json:"repo_path"`
+		Upstream   string           `json:"upstream"`
+		Branch     string           `json:"branch"`
+		Autostash  bool             `json:"autostash"`
+		RebasePlan []git.RebaseStep `json:"rebase_plan"`
+		Autosquash bool             `json:"autosquash"`
+		Continue   bool             `json:"continue"`
+		Abort      bool             `json:"abort"`
+		Skip       bool             `json:"skip"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
+	// Reword messages outlive a single call when the rebase stops on a
+	// conflict, so they are only removed once no rebase is in progress.
+	defer cleanupRebaseMessages(ctx, params.RepoPath)
+
 	// Validate mutually exclusive operations
 	opCount := 0
 	if params.Continue {
@@ -73,7 +81,7 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 	// Handle abort
 	if params.Abort {
 		if _, err := git.Run(ctx, params.RepoPath, "rebase", "--abort"); err != nil {
-			return command.TextErrorResult(fmt.Sprintf("git rebase --abort: %v", err)), nil
+			return gitErrorResult("git rebase --abort", err), nil
 		}
 
 		return command.JSONResult(git.RebaseResult{
@@ -85,15 +93,14 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 	if params.Continue {
 		out, err := git.Run(ctx, params.RepoPath, "rebase", "--continue")
 		if err != nil {
-			// Check if there are still conflicts
-			if strings.Contains(err.Error(), "fix conflicts") || strings.Contains(err.Error(), "still have conflicts") {
+			if git.KindOf(err) == git.KindMergeConflict {
 				conflicts := extractConflictFiles(ctx, params.RepoPath)
 				return command.JSONResult(git.RebaseResult{
 					Status:    "conflict",
 					Conflicts: conflicts,
 				}), nil
 			}
-			return command.TextErrorResult(fmt.Sprintf("git rebase --continue: %v", err)), nil
+			return gitErrorResult("git rebase --continue", err), nil
 		}
 
 		return command.JSONResult(git.RebaseResult{
@@ -106,7 +113,7 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 	if params.Skip {
 		out, err := git.Run(ctx, params.RepoPath, "rebase", "--skip")
 		if err != nil {
-			return command.TextErrorResult(fmt.Sprintf("git rebase --skip: %v", err)), nil
+			return gitErrorResult("git rebase --skip", err), nil
 		}
 
 		return command.JSONResult(git.RebaseResult{
@@ -132,16 +139,32 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 		}
 
 		// Check for existing rebase state
-		rebaseMergeDir := filepath.Join(params.RepoPath, ".git", "rebase-merge")
-		rebaseApplyDir := filepath.Join(params.RepoPath, ".git", "rebase-apply")
-		if _, err := os.Stat(rebaseMergeDir); err == nil {
+		if rebaseInProgress(ctx, params.RepoPath) {
 			return command.TextErrorResult("a rebase operation is already in progress; use continue, abort, or skip"), nil
 		}
-		if _, err := os.Stat(rebaseApplyDir); err == nil {
-			return command.TextErrorResult("a rebase operation is already in progress; use continue, abort, or skip"), nil
+
+		if len(params.RebasePlan) > 0 && params.Autosquash {
+			return command.TextErrorResult("autosquash cannot be combined with rebase_plan"), nil
 		}
 
 		gitArgs := []string{"rebase"}
+		var env []string
+
+		if len(params.RebasePlan) > 0 {
+			todoPath, err := prepareRebasePlan(ctx, params.RepoPath, params.Upstream, branchToRebase, params.RebasePlan)
+			if err != nil {
+				return gitErrorResult("rebase_plan", err), nil
+			}
+			defer os.Remove(todoPath)
+
+			gitArgs = append(gitArgs, "--interactive")
+			env = append(env, git.SequenceEditorEnv(todoPath))
+		} else if params.Autosquash {
+			// Accept the reordered todo as is; sequence.editor would
+			// otherwise take precedence over GIT_EDITOR.
+			gitArgs = append(gitArgs, "--interactive", "--autosquash")
+			env = append(env, "GIT_SEQUENCE_EDITOR=true")
+		}
 
 		if params.Autostash {
 			gitArgs = append(gitArgs, "--autostash")
@@ -153,10 +176,14 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 			gitArgs = append(gitArgs, params.Branch)
 		}
 
-		out, err := git.Run(ctx, params.RepoPath, gitArgs...)
+		upstreamHash := ""
+		if hashOut, err := git.Run(ctx, params.RepoPath, "rev-parse", "--verify", params.Upstream+"^{commit}"); err == nil {
+			upstreamHash = strings.TrimSpace(hashOut)
+		}
+
+		out, err := git.RunEnv(ctx, params.RepoPath, env, gitArgs...)
 		if err != nil {
-			// Check for conflicts
-			if strings.Contains(err.Error(), "CONFLICT") || strings.Contains(err.Error(), "could not apply") {
+			if git.KindOf(err) == git.KindMergeConflict {
 				conflicts := extractConflictFiles(ctx, params.RepoPath)
 				return command.JSONResult(git.RebaseResult{
 					Status:    "conflict",
@@ -165,7 +192,7 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 					Conflicts: conflicts,
 				}), nil
 			}
-			return command.TextErrorResult(fmt.Sprintf("git rebase: %v", err)), nil
+			return gitErrorResult("git rebase", err), nil
 		}
 
 		result := git.RebaseResult{
@@ -180,12 +207,128 @@ func handleGitRebase(ctx context.Context, args json.RawMessage, _ command.Prompt
 			result.Summary = ""
 		}
 
+		if len(params.RebasePlan) > 0 || params.Autosquash {
+			result.Commits = appliedCommits(ctx, params.RepoPath, upstreamHash)
+		}
+
 		return command.JSONResult(result), nil
 	}
 
 	return command.TextErrorResult("unexpected state: no operation specified"), nil
 }
 
+func rebaseInProgress(ctx context.Context, repoPath string) bool {
+	return git.GitPathExists(ctx, repoPath, "rebase-merge") || git.GitPathExists(ctx, repoPath, "rebase-apply")
+}
+
+// rebaseMessagesDir holds the messages applied by a rebase plan's exec lines.
+const rebaseMessagesDir = "grit-rebase-messages"
+
+func cleanupRebaseMessages(ctx context.Context, repoPath string) {
+	if rebaseInProgress(ctx, repoPath) {
+		return
+	}
+
+	if dir, err := git.GitPath(ctx, repoPath, rebaseMessagesDir); err == nil {
+		os.RemoveAll(dir)
+	}
+}
+
+// prepareRebasePlan validates a rebase plan against the commits that will be
+// rebased, writes its messages into the git directory, and returns the path
+// of a temporary todo file for GIT_SEQUENCE_EDITOR. Every commit must appear
+// exactly once so nothing is dropped by omission.
+func prepareRebasePlan(ctx context.Context, repoPath, upstream, branch string, plan []git.RebaseStep) (string, error) {
+	if err := git.ValidateRebasePlan(plan); err != nil {
+		return "", err
+	}
+
+	tip := branch
+	if tip == "" {
+		tip = "HEAD"
+	}
+
+	revOut, err := git.Run(ctx, repoPath, "rev-list", "--no-merges", "--reverse", upstream+".."+tip)
+	if err != nil {
+		return "", err
+	}
+
+	pending := make(map[string]bool)
+	for _, hash := range strings.Fields(revOut) {
+		pending[hash] = true
+	}
+
+	steps := make([]git.RebaseStep, len(plan))
+	for i, step := range plan {
+		hashOut, err := git.Run(ctx, repoPath, "rev-parse", "--verify", "--quiet", step.Commit+"^{commit}")
+		if err != nil {
+			return "", fmt.Errorf("step %d: unknown commit %q", i+1, step.Commit)
+		}
+
+		hash := strings.TrimSpace(hashOut)
+		if !pending[hash] {
+			return "", fmt.Errorf("step %d: commit %q is not in %s..%s or is listed twice", i+1, step.Commit, upstream, tip)
+		}
+		delete(pending, hash)
+
+		step.Commit = hash
+		steps[i] = step
+	}
+
+	if len(pending) > 0 {
+		missing := make([]string, 0, len(pending))
+		for hash := range pending {
+			missing = append(missing, hash[:12])
+		}
+		return "", fmt.Errorf("plan is missing commits %s; list them with drop to remove them", strings.Join(missing, ", "))
+	}
+
+	messagesDir, err := git.GitPath(ctx, repoPath, rebaseMessagesDir)
+	if err != nil {
+		return "", err
+	}
+
+	// exec lines run from the top of the worktree
+	if messagesDir, err = filepath.Abs(messagesDir); err != nil {
+		return "", err
+	}
+
+	if err := os.RemoveAll(messagesDir); err != nil {
+		return "", err
+	}
+
+	messagePaths := make(map[int]string)
+	for i, step := range steps {
+		if step.Message == "" {
+			continue
+		}
+
+		if err := os.MkdirAll(messagesDir, 0o755); err != nil {
+			return "", err
+		}
+
+		path := filepath.Join(messagesDir, fmt.Sprintf("step-%d", i+1))
+		if err := os.WriteFile(path, []byte(step.Message+"\n"), 0o644); err != nil {
+			return "", err
+		}
+
+		messagePaths[i] = path
+	}
+
+	todo, err := os.CreateTemp("", "grit-rebase-todo-*")
+	if err != nil {
+		return "", err
+	}
+	defer todo.Close()
+
+	if _, err := todo.WriteString(git.BuildRebaseTodo(steps, messagePaths)); err != nil {
+		os.Remove(todo.Name())
+		return "", err
+	}
+
+	return todo.Name(), nil
+}
+
 func extractConflictFiles(ctx context.Context, repoPath string) []string {
 	out, err := git.Run(ctx, repoPath, "diff", "--name-only", "--diff-filter=U")
 	if err != nil {
@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+func TestRebaseContinueConflict(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("rev-parse", "--git-path").Return("/nonexistent/.git/rebase-merge\n")
+	runner.On("rebase", "--continue").Fail("error: could not apply 1a2b3c4... change a\n")
+	runner.On("diff", "--name-only", "--diff-filter=U").Return("a.txt\n")
+
+	var result git.RebaseResult
+	decodeResult(t, runTool(t, runner, "rebase", `{"repo_path":"/repo","continue":true}`), &result)
+
+	if result.Status != "conflict" || len(result.Conflicts) != 1 || result.Conflicts[0] != "a.txt" {
+		t.Errorf("result = %+v, want conflict in a.txt", result)
+	}
+}
+
+func TestRebaseErrorCarriesKind(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("rev-parse", "--git-path").Return("/nonexistent/.git/rebase-merge\n")
+	runner.On("rebase", "--continue").Fail("fatal: No rebase in progress?\n")
+
+	result := runTool(t, runner, "rebase", `{"repo_path":"/repo","continue":true}`)
+	if !result.IsErr {
+		t.Fatalf("expected error result, got %+v", result)
+	}
+
+	payload, ok := result.JSON.(git.ErrorResult)
+	if !ok {
+		t.Fatalf("JSON = %T, want git.ErrorResult", result.JSON)
+	}
+
+	if payload.Kind != git.KindUnknown || payload.ExitCode != 1 || payload.Stderr != "fatal: No rebase in progress?\n" {
+		t.Errorf("payload = %+v", payload)
+	}
+}
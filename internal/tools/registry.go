@@ -1,8 +1,34 @@
 package tools
 
-import "github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+import (
+	"context"
+	"encoding/json"
+	"time"
 
-func RegisterAll() *command.App {
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// Timeouts bounds how long a tool call may run, including time spent
+// waiting for the repository lock. Zero means no limit.
+type Timeouts struct {
+	Default time.Duration
+	PerTool map[string]time.Duration
+}
+
+// For returns the timeout for the named tool.
+func (t Timeouts) For(name string) time.Duration {
+	if d, ok := t.PerTool[name]; ok {
+		return d
+	}
+	return t.Default
+}
+
+// RegisterAll registers every tool. Handlers run git through runner, which
+// is git.ExecRunner outside of tests. Mutating tools hold their
+// repository's lock for the whole call, so a multi-step tool such as rebase
+// never interleaves with another writer; read-only tools share it.
+func RegisterAll(runner git.Runner, timeouts Timeouts) *command.App {
 	app := command.NewApp("grit", "MCP server exposing git operations")
 	app.Version = "0.1.0"
 
@@ -14,6 +40,48 @@ func RegisterAll() *command.App {
 	registerRemoteCommands(app)
 	registerRevParseCommands(app)
 	registerRebaseCommands(app)
+	registerStashCommands(app)
+	registerTagCommands(app)
+	registerMergeCommands(app)
+	registerSequencerCommands(app)
+	registerWorktreeCommands(app)
+
+	locks := newRepoLocks()
+
+	for _, cmd := range app.AllCommands() {
+		if cmd.Run == nil {
+			continue
+		}
+
+		name := cmd.Name
+		run := cmd.Run
+		readOnly := readOnlyTools[name]
+		timeout := timeouts.For(name)
+		cmd.Run = func(ctx context.Context, args json.RawMessage, p command.Prompter) (*command.Result, error) {
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			unlock, err := locks.acquire(ctx, args, readOnly)
+			if err != nil {
+				return interruptedResult(name, err, timeout), nil
+			}
+			defer unlock()
+
+			result, err := run(git.WithRunner(ctx, runner), args, p)
+
+			// A handler that failed because its context ended reports why in
+			// whatever words its git error had; replace that with the
+			// timed_out or cancelled result. Calls that finished anyway stand.
+			if ctxErr := ctx.Err(); ctxErr != nil && (err != nil || result == nil || result.IsErr) {
+				return interruptedResult(name, ctxErr, timeout), nil
+			}
+
+			return result, err
+		}
+	}
 
 	return app
 }
@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// hangingRunner blocks until the invocation's context ends, then fails the
+// way ExecRunner does.
+type hangingRunner struct{}
+
+func (hangingRunner) Run(ctx context.Context, inv git.Invocation) (string, error) {
+	<-ctx.Done()
+	return "", &git.Error{Args: inv.Args, ExitCode: -1, Kind: git.ContextErrorKind(ctx.Err()), Err: ctx.Err()}
+}
+
+func errorKind(t *testing.T, result *command.Result) git.ErrorKind {
+	t.Helper()
+
+	payload, ok := result.JSON.(git.ErrorResult)
+	if !result.IsErr || !ok {
+		t.Fatalf("result = %+v, want error payload", result)
+	}
+	return payload.Kind
+}
+
+func TestToolTimeout(t *testing.T) {
+	app := RegisterAll(hangingRunner{}, Timeouts{
+		Default: time.Hour,
+		PerTool: map[string]time.Duration{"fetch": 20 * time.Millisecond},
+	})
+
+	cmd, _ := app.GetCommand("fetch")
+	result, err := cmd.Run(context.Background(), json.RawMessage(`{"repo_path":"/repo"}`), command.StubPrompter{})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	if kind := errorKind(t, result); kind != git.KindTimedOut {
+		t.Errorf("Kind = %q, want %q", kind, git.KindTimedOut)
+	}
+}
+
+func TestToolCancelled(t *testing.T) {
+	app := RegisterAll(hangingRunner{}, Timeouts{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	cmd, _ := app.GetCommand("fetch")
+	result, err := cmd.Run(ctx, json.RawMessage(`{"repo_path":"/repo"}`), command.StubPrompter{})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	if kind := errorKind(t, result); kind != git.KindCancelled {
+		t.Errorf("Kind = %q, want %q", kind, git.KindCancelled)
+	}
+}
+
+func TestTimeoutsFor(t *testing.T) {
+	timeouts := Timeouts{Default: time.Minute, PerTool: map[string]time.Duration{"push": 0}}
+
+	if d := timeouts.For("status"); d != time.Minute {
+		t.Errorf("For(status) = %s, want 1m", d)
+	}
+	if d := timeouts.For("push"); d != 0 {
+		t.Errorf("For(push) = %s, want 0", d)
+	}
+}
@@ -95,7 +95,7 @@ func handleGitFetch(ctx context.Context, args json.RawMessage, _ command.Prompte
 	}
 
 	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git fetch: %v", err)), nil
+		return gitErrorResult("git fetch", err), nil
 	}
 
 	remote := params.Remote
@@ -139,7 +139,7 @@ func handleGitPull(ctx context.Context, args json.RawMessage, _ command.Prompter
 
 	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git pull: %v", err)), nil
+		return gitErrorResult("git pull", err), nil
 	}
 
 	result := git.PullResult{
@@ -202,7 +202,7 @@ func handleGitPush(ctx context.Context, args json.RawMessage, _ command.Prompter
 	}
 
 	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git push: %v", err)), nil
+		return gitErrorResult("git push", err), nil
 	}
 
 	return command.JSONResult(git.MutationResult{
@@ -225,7 +225,7 @@ func handleGitRemoteList(ctx context.Context, args json.RawMessage, _ command.Pr
 
 	out, err := git.Run(ctx, params.RepoPath, "remote", "-v")
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git remote: %v", err)), nil
+		return gitErrorResult("git remote", err), nil
 	}
 
 	remotes := git.ParseRemoteList(out)
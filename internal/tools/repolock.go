@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// readOnlyTools never modify the repository, so any number of them may run
+// on one repository at once. Every other tool is treated as mutating.
+var readOnlyTools = map[string]bool{
+	"status":        true,
+	"diff":          true,
+	"log":           true,
+	"show":          true,
+	"blame":         true,
+	"git_rev_parse": true,
+	"branch_list":   true,
+	"remote_list":   true,
+	"stash_list":    true,
+	"stash_show":    true,
+	"tag_list":      true,
+	"worktree_list": true,
+}
+
+// repoLocks serialises mutating tool calls per repository, keyed by the
+// resolved git dir so that different paths into the same repository share
+// a lock while separate worktrees, which each have their own index, don't.
+// A lock is dropped once no call holds or waits for it, so the map only
+// grows with the repositories in use.
+type repoLocks struct {
+	mu    sync.Mutex
+	locks map[string]*repoLock
+}
+
+type repoLock struct {
+	*rwLock
+	refs int
+}
+
+func newRepoLocks() *repoLocks {
+	return &repoLocks{locks: make(map[string]*repoLock)}
+}
+
+// acquire locks the repository named by args' repo_path, exclusively unless
+// readOnly, and returns the function that releases it. Calls without a
+// repo_path are not locked; the handler reports the missing argument.
+func (r *repoLocks) acquire(ctx context.Context, args json.RawMessage, readOnly bool) (func(), error) {
+	var target struct {
+		RepoPath string `json:"repo_path"`
+	}
+	if err := json.Unmarshal(args, &target); err != nil || target.RepoPath == "" {
+		return func() {}, nil
+	}
+
+	key, err := git.ResolveGitDir(target.RepoPath)
+	if err != nil {
+		key = filepath.Clean(target.RepoPath)
+	}
+
+	r.mu.Lock()
+	lock := r.locks[key]
+	if lock == nil {
+		lock = &repoLock{rwLock: newRWLock()}
+		r.locks[key] = lock
+	}
+	lock.refs++
+	r.mu.Unlock()
+
+	var unlock func()
+	if readOnly {
+		unlock, err = lock.rlock(ctx)
+	} else {
+		unlock, err = lock.lock(ctx)
+	}
+
+	if err != nil {
+		r.release(key, lock)
+		return nil, err
+	}
+
+	return func() {
+		unlock()
+		r.release(key, lock)
+	}, nil
+}
+
+func (r *repoLocks) release(key string, lock *repoLock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(r.locks, key)
+	}
+}
+
+// rwLock is a readers-writer lock whose waits can be abandoned when the
+// context is done. Waiting writers block new readers so a steady stream of
+// status calls cannot starve a commit.
+type rwLock struct {
+	mu             sync.Mutex
+	readers        int
+	writer         bool
+	waitingWriters int
+	changed        chan struct{} // closed and replaced whenever the lock is released
+}
+
+func newRWLock() *rwLock {
+	return &rwLock{changed: make(chan struct{})}
+}
+
+func (l *rwLock) lock(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	l.waitingWriters++
+
+	for l.writer || l.readers > 0 {
+		if err := l.wait(ctx); err != nil {
+			l.waitingWriters--
+			l.broadcast()
+			l.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	l.waitingWriters--
+	l.writer = true
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		l.writer = false
+		l.broadcast()
+		l.mu.Unlock()
+	}, nil
+}
+
+func (l *rwLock) rlock(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+
+	for l.writer || l.waitingWriters > 0 {
+		if err := l.wait(ctx); err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	l.readers++
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		l.readers--
+		l.broadcast()
+		l.mu.Unlock()
+	}, nil
+}
+
+// wait releases l.mu until the lock changes hands or ctx is done, and
+// reacquires it before returning.
+func (l *rwLock) wait(ctx context.Context) error {
+	changed := l.changed
+	l.mu.Unlock()
+	defer l.mu.Lock()
+
+	select {
+	case <-changed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *rwLock) broadcast() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// gateRunner blocks every invocation until release is closed, tracking how
+// many run at once.
+type gateRunner struct {
+	release chan struct{}
+	started chan string
+
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func newGateRunner() *gateRunner {
+	return &gateRunner{release: make(chan struct{}), started: make(chan string, 16)}
+}
+
+func (g *gateRunner) Run(ctx context.Context, inv git.Invocation) (string, error) {
+	g.mu.Lock()
+	g.running++
+	g.peak = max(g.peak, g.running)
+	g.mu.Unlock()
+
+	g.started <- inv.Args[0]
+	<-g.release
+
+	g.mu.Lock()
+	g.running--
+	g.mu.Unlock()
+
+	return "", nil
+}
+
+// startTool runs the named tool in the background and returns a channel
+// that receives its result.
+func startTool(t *testing.T, app *command.App, name, args string) <-chan *command.Result {
+	t.Helper()
+
+	cmd, ok := app.GetCommand(name)
+	if !ok {
+		t.Fatalf("unknown tool %s", name)
+	}
+
+	done := make(chan *command.Result, 1)
+	go func() {
+		result, _ := cmd.Run(context.Background(), json.RawMessage(args), command.StubPrompter{})
+		done <- result
+	}()
+	return done
+}
+
+func waitStarted(t *testing.T, g *gateRunner) string {
+	t.Helper()
+
+	select {
+	case sub := <-g.started:
+		return sub
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for git to start")
+		return ""
+	}
+}
+
+func TestMutatingToolsSerialised(t *testing.T) {
+	g := newGateRunner()
+	app := RegisterAll(g, Timeouts{})
+
+	first := startTool(t, app, "add", `{"repo_path":"/repo","paths":["a"]}`)
+	waitStarted(t, g)
+
+	second := startTool(t, app, "add", `{"repo_path":"/repo","paths":["b"]}`)
+
+	select {
+	case <-g.started:
+		t.Fatal("second mutating call ran while the first held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(g.release)
+	<-first
+	waitStarted(t, g)
+	<-second
+
+	if g.peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1", g.peak)
+	}
+}
+
+func TestReadOnlyToolsConcurrent(t *testing.T) {
+	g := newGateRunner()
+	app := RegisterAll(g, Timeouts{})
+
+	first := startTool(t, app, "git_rev_parse", `{"repo_path":"/repo","ref":"HEAD"}`)
+	second := startTool(t, app, "git_rev_parse", `{"repo_path":"/repo","ref":"main"}`)
+	waitStarted(t, g)
+	waitStarted(t, g)
+
+	close(g.release)
+	<-first
+	<-second
+
+	if g.peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", g.peak)
+	}
+}
+
+func TestSeparateReposNotSerialised(t *testing.T) {
+	g := newGateRunner()
+	app := RegisterAll(g, Timeouts{})
+
+	first := startTool(t, app, "add", `{"repo_path":"/repo-a","paths":["a"]}`)
+	second := startTool(t, app, "add", `{"repo_path":"/repo-b","paths":["b"]}`)
+	waitStarted(t, g)
+	waitStarted(t, g)
+
+	close(g.release)
+	<-first
+	<-second
+}
+
+func TestLockWaitCancelled(t *testing.T) {
+	g := newGateRunner()
+	app := RegisterAll(g, Timeouts{})
+
+	first := startTool(t, app, "add", `{"repo_path":"/repo","paths":["a"]}`)
+	waitStarted(t, g)
+
+	cmd, _ := app.GetCommand("add")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result, err := cmd.Run(ctx, json.RawMessage(`{"repo_path":"/repo","paths":["b"]}`), command.StubPrompter{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !result.IsErr {
+		t.Errorf("result = %+v, want lock wait error", result)
+	}
+
+	close(g.release)
+	<-first
+}
+
+func TestRWLockWriterNotStarved(t *testing.T) {
+	l := newRWLock()
+	ctx := context.Background()
+
+	unlockReader, _ := l.rlock(ctx)
+
+	writerDone := make(chan struct{})
+	go func() {
+		unlock, _ := l.lock(ctx)
+		unlock()
+		close(writerDone)
+	}()
+
+	// Wait for the writer to queue, then check a new reader waits behind it.
+	for {
+		l.mu.Lock()
+		waiting := l.waitingWriters
+		l.mu.Unlock()
+		if waiting == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.rlock(readCtx); err == nil {
+		t.Fatal("new reader acquired the lock ahead of a waiting writer")
+	}
+
+	unlockReader()
+	<-writerDone
+}
+
+func TestRepoLocksDropped(t *testing.T) {
+	locks := newRepoLocks()
+	ctx := context.Background()
+
+	held, err := locks.acquire(ctx, json.RawMessage(`{"repo_path":"/repo-a"}`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/repo-b", "/repo-c"} {
+		unlock, err := locks.acquire(ctx, json.RawMessage(`{"repo_path":"`+path+`"}`), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unlock()
+	}
+
+	// A waiter that gives up must not leave its reference behind.
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := locks.acquire(waitCtx, json.RawMessage(`{"repo_path":"/repo-a"}`), false); err == nil {
+		t.Fatal("second writer acquired a held lock")
+	}
+
+	if n := len(locks.locks); n != 1 {
+		t.Errorf("locks = %d, want only the held one", n)
+	}
+
+	held()
+
+	if n := len(locks.locks); n != 0 {
+		t.Errorf("locks = %d after release, want 0", n)
+	}
+}
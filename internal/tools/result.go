@@ -1 +1,56 @@
 package tools
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/output"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// gitErrorResult reports a failed git invocation as an error result. When
+// err is a *git.Error the result carries its kind, exit code, and stderr as
+// JSON so clients can branch on the cause; other errors stay plain text.
+func gitErrorResult(op string, err error) *command.Result {
+	message := fmt.Sprintf("%s: %v", op, err)
+
+	var gitErr *git.Error
+	if !errors.As(err, &gitErr) {
+		return command.TextErrorResult(message)
+	}
+
+	return &command.Result{
+		Text: message,
+		JSON: git.ErrorResult{
+			Error:    message,
+			Kind:     gitErr.Kind,
+			ExitCode: gitErr.ExitCode,
+			Args:     gitErr.Args,
+			Stderr:   output.LimitStderr(gitErr.Stderr).Content,
+			Lock:     gitErr.Lock,
+		},
+		IsErr: true,
+	}
+}
+
+// interruptedResult reports a tool call stopped by its timeout or by the
+// client cancelling it.
+func interruptedResult(name string, err error, timeout time.Duration) *command.Result {
+	kind := git.ContextErrorKind(err)
+
+	message := fmt.Sprintf("%s was cancelled", name)
+	if kind == git.KindTimedOut {
+		message = fmt.Sprintf("%s timed out after %s", name, timeout)
+	}
+
+	return &command.Result{
+		Text: message,
+		JSON: git.ErrorResult{
+			Error: message,
+			Kind:  kind,
+		},
+		IsErr: true,
+	}
+}
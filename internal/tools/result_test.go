@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+func TestGitErrorResultKinds(t *testing.T) {
+	tests := []struct {
+		tool   string
+		args   string
+		on     []string
+		stderr string
+		want   git.ErrorKind
+	}{
+		{"git_rev_parse", `{"repo_path":"/repo","ref":"nope"}`, []string{"rev-parse"}, "fatal: Needed a single revision\n", git.KindUnknownRevision},
+		{"push", `{"repo_path":"/repo"}`, []string{"push"}, " ! [rejected]        feature -> feature (non-fast-forward)\n", git.KindNonFastForward},
+		{"branch_create", `{"repo_path":"/repo","name":"feature"}`, []string{"branch"}, "fatal: a branch named 'feature' already exists\n", git.KindAlreadyExists},
+		{"status", `{"repo_path":"/repo"}`, []string{"status"}, "fatal: not a git repository (or any of the parent directories): .git\n", git.KindNotARepository},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On(tt.on...).Fail(tt.stderr)
+
+			result := runTool(t, runner, tt.tool, tt.args)
+
+			payload, ok := result.JSON.(git.ErrorResult)
+			if !result.IsErr || !ok {
+				t.Fatalf("result = %+v, want error payload", result)
+			}
+			if payload.Kind != tt.want {
+				t.Errorf("Kind = %q, want %q", payload.Kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestShowReportsFallbackError(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("show", "--no-patch").Fail("fatal: bad object\n")
+	runner.On("show", "nope").Fail("fatal: ambiguous argument 'nope': unknown revision or path not in the working tree.\n")
+
+	result := runTool(t, runner, "show", `{"repo_path":"/repo","ref":"nope"}`)
+
+	payload, ok := result.JSON.(git.ErrorResult)
+	if !result.IsErr || !ok {
+		t.Fatalf("result = %+v, want error payload", result)
+	}
+	if payload.Kind != git.KindUnknownRevision || len(payload.Args) != 2 || payload.Args[1] != "nope" {
+		t.Errorf("payload = %+v, want the fallback show's unknown_revision error", payload)
+	}
+}
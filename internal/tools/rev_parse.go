@@ -37,7 +37,7 @@ func handleGitRevParse(ctx context.Context, args json.RawMessage, _ command.Prom
 
 	out, err := git.Run(ctx, params.RepoPath, "rev-parse", "--verify", params.Ref)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git rev-parse: %v", err)), nil
+		return gitErrorResult("git rev-parse", err), nil
 	}
 
 	return command.JSONResult(git.RevParseResult{
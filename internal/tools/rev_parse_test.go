@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+func TestRevParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		output  string
+		fail    string
+		want    string
+		wantErr string
+	}{
+		{"resolves", "HEAD~1", "0123456789abcdef0123456789abcdef01234567\n", "", "0123456789abcdef0123456789abcdef01234567", ""},
+		{"unknown ref", "nope", "", "fatal: Needed a single revision", "", "git rev-parse: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			response := runner.On("rev-parse").Return(tt.output)
+			if tt.fail != "" {
+				response.Fail(tt.fail)
+			}
+
+			result := runTool(t, runner, "git_rev_parse", `{"repo_path":"/repo","ref":"`+tt.ref+`"}`)
+
+			if got := runner.Calls()[0]; got.Dir != "/repo" || !slices.Equal(got.Args, []string{"rev-parse", "--verify", tt.ref}) {
+				t.Errorf("invocation = %+v, want rev-parse --verify %s in /repo", got, tt.ref)
+			}
+
+			if tt.wantErr != "" {
+				if !result.IsErr || !strings.HasPrefix(result.Text, tt.wantErr) {
+					t.Errorf("result = %+v, want error %q", result, tt.wantErr)
+				}
+				return
+			}
+
+			var resolved git.RevParseResult
+			decodeResult(t, result, &resolved)
+			if resolved.Resolved != tt.want || resolved.Ref != tt.ref {
+				t.Errorf("result = %+v, want %s for %s", resolved, tt.want, tt.ref)
+			}
+		})
+	}
+}
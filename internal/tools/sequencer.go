@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// sequencerOp describes a git command that drives the sequencer
+// (cherry-pick and revert share the same continue/abort/skip/quit flow).
+type sequencerOp struct {
+	name       string
+	subcommand string
+	headFile   string
+}
+
+var (
+	cherryPickOp = sequencerOp{name: "cherry_pick", subcommand: "cherry-pick", headFile: "CHERRY_PICK_HEAD"}
+	revertOp     = sequencerOp{name: "revert", subcommand: "revert", headFile: "REVERT_HEAD"}
+)
+
+func registerSequencerCommands(app *command.App) {
+	app.AddCommand(&command.Command{
+		Name:        "cherry_pick",
+		Description: command.Description{Short: "Apply the changes of existing commits onto the current branch"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "commits", Type: command.Array, Description: "Commits or ranges to pick, in order (e.g. ['abc1234'] or ['main~3..main'])"},
+			{Name: "record_origin", Type: command.Bool, Description: "Append a '(cherry picked from commit ...)' line to each message (-x)"},
+			{Name: "no_commit", Type: command.Bool, Description: "Apply changes to the index and working tree without committing (--no-commit)"},
+			{Name: "mainline", Type: command.Int, Description: "Parent number to diff against when picking a merge commit (-m)"},
+			{Name: "continue", Type: command.Bool, Description: "Continue after resolving conflicts"},
+			{Name: "abort", Type: command.Bool, Description: "Abort and return to the pre-sequence state"},
+			{Name: "skip", Type: command.Bool, Description: "Skip the current commit and continue with the rest"},
+			{Name: "quit", Type: command.Bool, Description: "Forget the in-progress operation, keeping commits already applied"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git cherry-pick"}, UseWhen: "cherry-picking commits"},
+		},
+		Run: handleGitCherryPick,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "revert",
+		Description: command.Description{Short: "Create commits that undo the changes of existing commits"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "commits", Type: command.Array, Description: "Commits or ranges to revert, in order (e.g. ['abc1234'] or ['HEAD~3..HEAD'])"},
+			{Name: "no_commit", Type: command.Bool, Description: "Apply the inverse changes without committing (--no-commit)"},
+			{Name: "mainline", Type: command.Int, Description: "Parent number to keep when reverting a merge commit (-m)"},
+			{Name: "continue", Type: command.Bool, Description: "Continue after resolving conflicts"},
+			{Name: "abort", Type: command.Bool, Description: "Abort and return to the pre-sequence state"},
+			{Name: "skip", Type: command.Bool, Description: "Skip the current commit and continue with the rest"},
+			{Name: "quit", Type: command.Bool, Description: "Forget the in-progress operation, keeping commits already reverted"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git revert"}, UseWhen: "reverting commits"},
+		},
+		Run: handleGitRevert,
+	})
+}
+
+func handleGitCherryPick(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	return runSequencer(ctx, args, cherryPickOp)
+}
+
+func handleGitRevert(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	return runSequencer(ctx, args, revertOp)
+}
+
+func runSequencer(ctx context.Context, args json.RawMessage, op sequencerOp) (*command.Result, error) {
+	var params struct {
+		RepoPath     string   `json:"repo_path"`
+		Commits      []string `json:"commits"`
+		RecordOrigin bool     `json:"record_origin"`
+		NoCommit     bool     `json:"no_commit"`
+		Mainline     int      `json:"mainline"`
+		Continue     bool     `json:"continue"`
+		Abort        bool     `json:"abort"`
+		Skip         bool     `json:"skip"`
+		Quit         bool     `json:"quit"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	// Validate mutually exclusive operations
+	opCount := 0
+	if params.Continue {
+		opCount++
+	}
+	if params.Abort {
+		opCount++
+	}
+	if params.Skip {
+		opCount++
+	}
+	if params.Quit {
+		opCount++
+	}
+	if len(params.Commits) > 0 {
+		opCount++
+	}
+
+	if opCount > 1 {
+		return command.TextErrorResult("only one of commits, continue, abort, skip, or quit can be specified"), nil
+	}
+
+	if opCount == 0 {
+		return command.TextErrorResult(fmt.Sprintf("must specify commits (for new %s) or continue/abort/skip/quit (for existing %s)", op.subcommand, op.subcommand)), nil
+	}
+
+	// Handle abort and quit
+	if params.Abort || params.Quit {
+		flag, status := "--abort", "aborted"
+		if params.Quit {
+			flag, status = "--quit", "quit"
+		}
+
+		if _, err := git.Run(ctx, params.RepoPath, op.subcommand, flag); err != nil {
+			return gitErrorResult(fmt.Sprintf("git %s %s", op.subcommand, flag), err), nil
+		}
+
+		return command.JSONResult(git.SequencerResult{
+			Status:    status,
+			Operation: op.name,
+		}), nil
+	}
+
+	before := resolveHead(ctx, params.RepoPath)
+
+	var gitArgs []string
+	status := "completed"
+
+	switch {
+	case params.Continue:
+		gitArgs = []string{op.subcommand, "--continue"}
+	case params.Skip:
+		gitArgs = []string{op.subcommand, "--skip"}
+		status = "skipped"
+	default:
+		// Check for existing sequencer state
+		for _, name := range []string{"CHERRY_PICK_HEAD", "REVERT_HEAD", "sequencer"} {
+			if git.GitPathExists(ctx, params.RepoPath, name) {
+				return command.TextErrorResult("a cherry-pick or revert is already in progress; use continue, abort, skip, or quit"), nil
+			}
+		}
+
+		gitArgs = []string{op.subcommand}
+
+		if params.RecordOrigin && op == cherryPickOp {
+			gitArgs = append(gitArgs, "-x")
+		}
+
+		if params.NoCommit {
+			gitArgs = append(gitArgs, "--no-commit")
+			status = "staged"
+		}
+
+		if params.Mainline > 0 {
+			gitArgs = append(gitArgs, fmt.Sprintf("--mainline=%d", params.Mainline))
+		}
+
+		gitArgs = append(gitArgs, params.Commits...)
+	}
+
+	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
+	if err != nil {
+		if conflicts := extractConflictFiles(ctx, params.RepoPath); len(conflicts) > 0 {
+			result := git.SequencerResult{
+				Status:    "conflict",
+				Operation: op.name,
+				Applied:   appliedCommits(ctx, params.RepoPath, before),
+				Conflicts: conflicts,
+			}
+
+			currentOut, err := git.Run(ctx, params.RepoPath, "rev-parse", "--verify", "--quiet", op.headFile)
+			if err == nil {
+				result.CurrentCommit = strings.TrimSpace(currentOut)
+			}
+
+			return command.JSONResult(result), nil
+		}
+		return gitErrorResult("git "+op.subcommand, err), nil
+	}
+
+	return command.JSONResult(git.SequencerResult{
+		Status:    status,
+		Operation: op.name,
+		Applied:   appliedCommits(ctx, params.RepoPath, before),
+		Summary:   strings.TrimSpace(out),
+	}), nil
+}
+
+// appliedCommits lists the commits created on top of before, oldest first.
+func appliedCommits(ctx context.Context, repoPath, before string) []git.AppliedCommit {
+	if before == "" {
+		return nil
+	}
+
+	out, err := git.Run(ctx, repoPath, "log", "--reverse", fmt.Sprintf("--format=%s", git.LogFormat), before+"..HEAD")
+	if err != nil {
+		return nil
+	}
+
+	var applied []git.AppliedCommit
+	for _, entry := range git.ParseLog(out) {
+		applied = append(applied, git.AppliedCommit{
+			Hash:    entry.Hash,
+			Subject: entry.Subject,
+		})
+	}
+
+	return applied
+}
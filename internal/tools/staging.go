@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
 	"github.com/friedenberg/grit/internal/git"
@@ -35,6 +37,22 @@ func registerStagingCommands(app *command.App) {
 		},
 		Run: handleGitReset,
 	})
+
+	app.AddCommand(&command.Command{
+		Name:        "stage_hunks",
+		Description: command.Description{Short: "List the hunks of a file's diff, or stage/unstage selected hunks and lines"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "path", Type: command.String, Description: "File path to operate on (relative to repo root)", Required: true},
+			{Name: "action", Type: command.String, Description: "list (default), stage, or unstage. list shows unstaged hunks; pass unstage with no selection to list staged hunks"},
+			{Name: "hunks", Type: command.Array, Description: "IDs of whole hunks to stage or unstage, as returned by list"},
+			{Name: "lines", Type: command.Array, Description: "Individual changed lines to stage or unstage, as '<hunk_id>:<start>[-<end>]' using 1-based positions within the hunk's lines"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git add -p", "git add --patch", "git reset -p", "git reset --patch"}, UseWhen: "staging or unstaging part of a file"},
+		},
+		Run: handleGitStageHunks,
+	})
 }
 
 func handleGitAdd(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
@@ -51,7 +69,7 @@ func handleGitAdd(ctx context.Context, args json.RawMessage, _ command.Prompter)
 	gitArgs = append(gitArgs, params.Paths...)
 
 	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git add: %v", err)), nil
+		return gitErrorResult("git add", err), nil
 	}
 
 	return command.JSONResult(git.MutationResult{
@@ -74,7 +92,7 @@ func handleGitReset(ctx context.Context, args json.RawMessage, _ command.Prompte
 	gitArgs = append(gitArgs, params.Paths...)
 
 	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git reset: %v", err)), nil
+		return gitErrorResult("git reset", err), nil
 	}
 
 	return command.JSONResult(git.MutationResult{
@@ -82,3 +100,184 @@ func handleGitReset(ctx context.Context, args json.RawMessage, _ command.Prompte
 		Paths:  params.Paths,
 	}), nil
 }
+
+func handleGitStageHunks(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string   `json:"repo_path"`
+		Path     string   `json:"path"`
+		Action   string   `json:"action"`
+		Hunks    []string `json:"hunks"`
+		Lines    []string `json:"lines"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	action := params.Action
+	if action == "" {
+		action = "list"
+	}
+
+	if action != "list" && action != "stage" && action != "unstage" {
+		return command.TextErrorResult(fmt.Sprintf("invalid action %q: must be list, stage, or unstage", params.Action)), nil
+	}
+
+	hasSelection := len(params.Hunks) > 0 || len(params.Lines) > 0
+
+	if action == "list" && hasSelection {
+		return command.TextErrorResult("hunks and lines are only used by the stage and unstage actions; pass action stage or unstage"), nil
+	}
+
+	if action == "stage" && !hasSelection {
+		return command.TextErrorResult("stage requires hunks or lines to select what to stage"), nil
+	}
+
+	// Unstaging works on the staged diff and applies the patch in reverse.
+	reverse := action == "unstage"
+
+	fp, err := fileHunks(ctx, params.RepoPath, params.Path, reverse)
+	if err != nil {
+		return gitErrorResult("git diff", err), nil
+	}
+
+	// unstage with no selection lists the staged hunks.
+	if !hasSelection {
+		return command.JSONResult(git.HunkStageResult{
+			Status: "listed",
+			Path:   params.Path,
+			Hunks:  fp.Hunks,
+		}), nil
+	}
+
+	selected, err := parseHunkSelection(params.Hunks, params.Lines)
+	if err != nil {
+		return command.TextErrorResult(err.Error()), nil
+	}
+
+	known := make(map[string]bool, len(fp.Hunks))
+	for _, hunk := range fp.Hunks {
+		known[hunk.ID] = true
+	}
+
+	applied := make([]string, 0, len(selected))
+	for id := range selected {
+		if !known[id] {
+			return command.TextErrorResult(fmt.Sprintf("unknown hunk id %q; list the hunks again to get current ids", id)), nil
+		}
+	}
+	for _, hunk := range fp.Hunks {
+		if _, ok := selected[hunk.ID]; ok {
+			applied = append(applied, hunk.ID)
+		}
+	}
+
+	patch, err := git.BuildHunkPatch(fp, selected, reverse)
+	if err != nil {
+		return command.TextErrorResult(err.Error()), nil
+	}
+
+	if patch == "" {
+		return command.TextErrorResult("selection contains no changed lines"), nil
+	}
+
+	if err := applyCachedPatch(ctx, params.RepoPath, patch, reverse); err != nil {
+		return gitErrorResult("git apply", err), nil
+	}
+
+	remaining, err := fileHunks(ctx, params.RepoPath, params.Path, reverse)
+	if err != nil {
+		return gitErrorResult("git diff", err), nil
+	}
+
+	status := "staged"
+	if reverse {
+		status = "unstaged"
+	}
+
+	result := git.HunkStageResult{
+		Status:  status,
+		Path:    params.Path,
+		Applied: applied,
+		Hunks:   remaining.Hunks,
+	}
+
+	numstatArgs := []string{"diff", "--numstat"}
+	if reverse {
+		numstatArgs = append(numstatArgs, "--cached")
+	}
+	numstatArgs = append(numstatArgs, "--", params.Path)
+
+	if numstatOut, err := git.Run(ctx, params.RepoPath, numstatArgs...); err == nil {
+		if stats := git.ParseDiffNumstat(numstatOut); len(stats) > 0 {
+			result.Remaining = &stats[0]
+		}
+	}
+
+	return command.JSONResult(result), nil
+}
+
+// fileHunks returns the unstaged hunks of path, or the staged hunks when
+// cached is true.
+func fileHunks(ctx context.Context, repoPath, path string, cached bool) (git.FilePatch, error) {
+	gitArgs := []string{"diff"}
+	if cached {
+		gitArgs = append(gitArgs, "--cached")
+	}
+	gitArgs = append(gitArgs, "--", path)
+
+	out, err := git.Run(ctx, repoPath, gitArgs...)
+	if err != nil {
+		return git.FilePatch{}, err
+	}
+
+	return git.ParseFilePatch(path, out), nil
+}
+
+// parseHunkSelection builds the hunk ID to line positions map used by
+// git.BuildHunkPatch. Whole hunks take precedence over line selections.
+func parseHunkSelection(hunks, lines []string) (map[string][]int, error) {
+	selected := make(map[string][]int)
+
+	for _, spec := range lines {
+		id, rangeSpec, ok := strings.Cut(spec, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid line selection %q: expected <hunk_id>:<start>[-<end>]", spec)
+		}
+
+		startSpec, endSpec, isRange := strings.Cut(rangeSpec, "-")
+		start, err := strconv.Atoi(startSpec)
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid line selection %q: bad start position", spec)
+		}
+
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(endSpec)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid line selection %q: bad end position", spec)
+			}
+		}
+
+		for pos := start; pos <= end; pos++ {
+			selected[id] = append(selected[id], pos)
+		}
+	}
+
+	for _, id := range hunks {
+		selected[id] = nil
+	}
+
+	return selected, nil
+}
+
+func applyCachedPatch(ctx context.Context, repoPath, patch string, reverse bool) error {
+	gitArgs := []string{"apply", "--cached"}
+	if reverse {
+		gitArgs = append(gitArgs, "--reverse")
+	}
+	gitArgs = append(gitArgs, "-")
+
+	_, err := git.RunInput(ctx, repoPath, patch, gitArgs...)
+	return err
+}
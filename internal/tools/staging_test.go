@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+const oneHunkPatch = `diff --git a/f b/f
+index 4f7cbe7..016fa61 100644
+--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+-a
++A
+ b
+`
+
+func TestStageHunksInvalidSelection(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+	}{
+		{"selection without action", `{"repo_path":"/repo","path":"f","hunks":["h1"]}`},
+		{"lines with list", `{"repo_path":"/repo","path":"f","action":"list","lines":["h1:1"]}`},
+		{"stage without selection", `{"repo_path":"/repo","path":"f","action":"stage"}`},
+		{"unknown action", `{"repo_path":"/repo","path":"f","action":"drop"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			result := runTool(t, runner, "stage_hunks", tt.args)
+
+			if !result.IsErr {
+				t.Errorf("result = %+v, want error", result)
+			}
+			if len(runner.Calls()) != 0 {
+				t.Errorf("git ran for invalid arguments: %v", runner.Calls())
+			}
+		})
+	}
+}
+
+func TestStageHunksList(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantDiff []string
+	}{
+		{"default", `{"repo_path":"/repo","path":"f"}`, []string{"diff", "--", "f"}},
+		{"unstage without selection", `{"repo_path":"/repo","path":"f","action":"unstage"}`, []string{"diff", "--cached", "--", "f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("diff").Return(oneHunkPatch)
+
+			var result git.HunkStageResult
+			decodeResult(t, runTool(t, runner, "stage_hunks", tt.args), &result)
+
+			if result.Status != "listed" || len(result.Hunks) != 1 {
+				t.Errorf("result = %+v, want one listed hunk", result)
+			}
+
+			calls := runner.Calls()
+			if len(calls) != 1 || !slices.Equal(calls[0].Args, tt.wantDiff) {
+				t.Errorf("calls = %v, want only git %v", calls, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestStageHunksStage(t *testing.T) {
+	id := git.ParseFilePatch("f", oneHunkPatch).Hunks[0].ID
+
+	runner := gittest.NewFakeRunner(t)
+	runner.On("diff", "--", "f").Return(oneHunkPatch).Times(1)
+	runner.On("apply", "--cached", "-")
+	runner.On("diff", "--", "f").Return("")
+	runner.On("diff", "--numstat").Return("")
+
+	var result git.HunkStageResult
+	decodeResult(t, runTool(t, runner, "stage_hunks", `{"repo_path":"/repo","path":"f","action":"stage","hunks":["`+id+`"]}`), &result)
+
+	if result.Status != "staged" || !slices.Equal(result.Applied, []string{id}) || len(result.Hunks) != 0 {
+		t.Errorf("result = %+v, want the hunk staged", result)
+	}
+
+	if !runner.Called("apply", "--cached", "-") {
+		t.Errorf("calls = %v, want git apply --cached", runner.Calls())
+	}
+}
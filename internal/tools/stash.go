@@ -0,0 +1,333 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+func registerStashCommands(app *command.App) {
+	app.AddCommand(&command.Command{
+		Name:        "stash_push",
+		Description: command.Description{Short: "Stash uncommitted changes"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "message", Type: command.String, Description: "Description for the stash entry"},
+			{Name: "include_untracked", Type: command.Bool, Description: "Also stash untracked files (-u)"},
+			{Name: "keep_index", Type: command.Bool, Description: "Leave staged changes in the index (--keep-index)"},
+			{Name: "paths", Type: command.Array, Description: "Limit the stash to these paths (relative to repo root)"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git stash push", "git stash save", "git stash"}, UseWhen: "stashing uncommitted changes"},
+		},
+		Run: handleGitStashPush,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "stash_list",
+		Description: command.Description{Short: "List stash entries as structured JSON"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "limit", Type: command.Int, Description: "Maximum number of stash entries per page (default 100)"},
+			{Name: "cursor", Type: command.String, Description: "next_cursor from a previous call, to fetch the following page; pass the same other arguments"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git stash list"}, UseWhen: "listing stash entries"},
+		},
+		Run: handleGitStashList,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "stash_show",
+		Description: command.Description{Short: "Show the changes recorded in a stash entry"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "index", Type: command.Int, Description: "Stash index to show (default 0, i.e. stash@{0})"},
+			{Name: "include_untracked", Type: command.Bool, Description: "Include untracked files recorded in the stash"},
+			{Name: "stat_only", Type: command.Bool, Description: "Show only diffstat summary"},
+			{Name: "context_lines", Type: command.Int, Description: "Number of context lines around each change (git --unified=N, default 3)"},
+			{Name: "max_patch_lines", Type: command.Int, Description: "Maximum number of patch output lines. Output is truncated with a truncated flag when exceeded."},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git stash show"}, UseWhen: "inspecting a stash entry"},
+		},
+		Run: handleGitStashShow,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "stash_apply",
+		Description: command.Description{Short: "Apply a stash entry to the working tree, keeping it in the stash list"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "index", Type: command.Int, Description: "Stash index to apply (default 0, i.e. stash@{0})"},
+			{Name: "restore_index", Type: command.Bool, Description: "Also restore the staged state of the stash (--index)"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git stash apply"}, UseWhen: "applying a stash entry"},
+		},
+		Run: handleGitStashApply,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "stash_pop",
+		Description: command.Description{Short: "Apply a stash entry and remove it from the stash list (kept on conflict)"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "index", Type: command.Int, Description: "Stash index to pop (default 0, i.e. stash@{0})"},
+			{Name: "restore_index", Type: command.Bool, Description: "Also restore the staged state of the stash (--index)"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git stash pop"}, UseWhen: "restoring stashed changes"},
+		},
+		Run: handleGitStashPop,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "stash_drop",
+		Description: command.Description{Short: "Remove a single stash entry"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "index", Type: command.Int, Description: "Stash index to drop (default 0, i.e. stash@{0})"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git stash drop"}, UseWhen: "removing a stash entry"},
+		},
+		Run: handleGitStashDrop,
+	})
+}
+
+func stashRef(index int) string {
+	return fmt.Sprintf("stash@{%d}", index)
+}
+
+func handleGitStashPush(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath         string   `json:"repo_path"`
+		Message          string   `json:"message"`
+		IncludeUntracked bool     `json:"include_untracked"`
+		KeepIndex        bool     `json:"keep_index"`
+		Paths            []string `json:"paths"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	gitArgs := []string{"stash", "push"}
+
+	if params.IncludeUntracked {
+		gitArgs = append(gitArgs, "--include-untracked")
+	}
+
+	if params.KeepIndex {
+		gitArgs = append(gitArgs, "--keep-index")
+	}
+
+	if params.Message != "" {
+		gitArgs = append(gitArgs, "--message", params.Message)
+	}
+
+	if len(params.Paths) > 0 {
+		gitArgs = append(gitArgs, "--")
+		gitArgs = append(gitArgs, params.Paths...)
+	}
+
+	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
+	if err != nil {
+		return gitErrorResult("git stash push", err), nil
+	}
+
+	if strings.Contains(out, "No local changes to save") {
+		return command.JSONResult(git.StashResult{
+			Status: "no_changes",
+		}), nil
+	}
+
+	return command.JSONResult(git.StashResult{
+		Status:  "stashed",
+		Ref:     stashRef(0),
+		Paths:   params.Paths,
+		Summary: strings.TrimSpace(out),
+	}), nil
+}
+
+func handleGitStashList(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Limit    int    `json:"limit"`
+		Cursor   string `json:"cursor"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	cur, err := decodeCursor("stash_list", params.Cursor)
+	if err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid cursor: %v", err)), nil
+	}
+
+	out, err := git.Run(ctx, params.RepoPath, "stash", "list", fmt.Sprintf("--format=%s", git.StashListFormat))
+	if err != nil {
+		return gitErrorResult("git stash list", err), nil
+	}
+
+	// Stashes are keyed by commit rather than stash@{n}, which shifts
+	// with every push.
+	var result git.StashListResult
+	result.Stashes, result.NextCursor = pageAfter(git.ParseStashList(out), cur, pageSize(params.Limit), func(s git.StashEntry) string {
+		return s.Hash
+	})
+
+	return command.JSONResult(result), nil
+}
+
+func handleGitStashShow(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath         string `json:"repo_path"`
+		Index            int    `json:"index"`
+		IncludeUntracked bool   `json:"include_untracked"`
+		StatOnly         bool   `json:"stat_only"`
+		ContextLines     *int   `json:"context_lines"`
+		MaxPatchLines    int    `json:"max_patch_lines"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	ref := stashRef(params.Index)
+
+	numstatArgs := []string{"stash", "show", "--numstat"}
+	if params.IncludeUntracked {
+		numstatArgs = append(numstatArgs, "--include-untracked")
+	}
+	numstatArgs = append(numstatArgs, ref)
+
+	numstatOut, err := git.Run(ctx, params.RepoPath, numstatArgs...)
+	if err != nil {
+		return gitErrorResult("git stash show", err), nil
+	}
+
+	stats := git.ParseDiffNumstat(numstatOut)
+
+	var summary git.DiffSummary
+	summary.TotalFiles = len(stats)
+	for _, s := range stats {
+		summary.TotalAdditions += s.Additions
+		summary.TotalDeletions += s.Deletions
+	}
+
+	result := git.DiffResult{
+		Stats:   stats,
+		Summary: summary,
+	}
+
+	if !params.StatOnly {
+		patchArgs := []string{"stash", "show", "--patch"}
+		if params.ContextLines != nil {
+			patchArgs = append(patchArgs, fmt.Sprintf("--unified=%d", *params.ContextLines))
+		}
+		if params.IncludeUntracked {
+			patchArgs = append(patchArgs, "--include-untracked")
+		}
+		patchArgs = append(patchArgs, ref)
+
+		patchOut, err := git.Run(ctx, params.RepoPath, patchArgs...)
+		if err != nil {
+			return gitErrorResult("git stash show", err), nil
+		}
+
+		patch, truncated, truncatedAt := git.TruncatePatch(patchOut, params.MaxPatchLines)
+		result.Patch = patch
+		result.Truncated = truncated
+		result.TruncatedAtLine = truncatedAt
+	}
+
+	return command.JSONResult(result), nil
+}
+
+func handleGitStashApply(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath     string `json:"repo_path"`
+		Index        int    `json:"index"`
+		RestoreIndex bool   `json:"restore_index"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	return runStashApply(ctx, params.RepoPath, "apply", stashRef(params.Index), params.RestoreIndex, "applied")
+}
+
+func handleGitStashPop(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath     string `json:"repo_path"`
+		Index        int    `json:"index"`
+		RestoreIndex bool   `json:"restore_index"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	return runStashApply(ctx, params.RepoPath, "pop", stashRef(params.Index), params.RestoreIndex, "popped")
+}
+
+// runStashApply runs `git stash apply` or `git stash pop` and reports merge
+// conflicts the same way the rebase tool does. On conflict git keeps the
+// entry in the stash list, even for pop.
+func runStashApply(ctx context.Context, repoPath, subcommand, ref string, restoreIndex bool, status string) (*command.Result, error) {
+	gitArgs := []string{"stash", subcommand}
+
+	if restoreIndex {
+		gitArgs = append(gitArgs, "--index")
+	}
+
+	gitArgs = append(gitArgs, ref)
+
+	out, err := git.Run(ctx, repoPath, gitArgs...)
+	if err != nil {
+		if conflicts := extractConflictFiles(ctx, repoPath); len(conflicts) > 0 {
+			return command.JSONResult(git.StashResult{
+				Status:    "conflict",
+				Ref:       ref,
+				Conflicts: conflicts,
+			}), nil
+		}
+		return gitErrorResult("git stash "+subcommand, err), nil
+	}
+
+	return command.JSONResult(git.StashResult{
+		Status:  status,
+		Ref:     ref,
+		Summary: strings.TrimSpace(out),
+	}), nil
+}
+
+func handleGitStashDrop(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Index    int    `json:"index"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	ref := stashRef(params.Index)
+
+	if _, err := git.Run(ctx, params.RepoPath, "stash", "drop", ref); err != nil {
+		return gitErrorResult("git stash drop", err), nil
+	}
+
+	return command.JSONResult(git.StashResult{
+		Status: "dropped",
+		Ref:    ref,
+	}), nil
+}
@@ -15,6 +15,9 @@ func registerStatusCommands(app *command.App) {
 		Description: command.Description{Short: "Show working tree status with machine-readable output"},
 		Params: []command.Param{
 			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "untracked", Type: command.String, Description: "Untracked files to list: no, normal (default; untracked directories collapsed), or all (every file)"},
+			{Name: "ignored", Type: command.Bool, Description: "Also list ignored files"},
+			{Name: "paths", Type: command.Array, Description: "Limit status to specific paths"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git status"}, UseWhen: "checking repository status"},
@@ -32,7 +35,10 @@ func registerStatusCommands(app *command.App) {
 			{Name: "paths", Type: command.Array, Description: "Limit diff to specific paths"},
 			{Name: "stat_only", Type: command.Bool, Description: "Show only diffstat summary"},
 			{Name: "context_lines", Type: command.Int, Description: "Number of context lines around each change (git --unified=N, default 3)"},
-			{Name: "max_patch_lines", Type: command.Int, Description: "Maximum number of patch output lines. Output is truncated with a truncated flag when exceeded."},
+			{Name: "max_patch_lines", Type: command.Int, Description: "Maximum number of patch output lines. Output is truncated with a truncated flag when exceeded. Structured output is cut at hunk boundaries."},
+			{Name: "format", Type: command.String, Description: "Patch format: patch (default, raw unified diff) or structured (files, hunks, and lines with line numbers)"},
+			{Name: "budget_lines", Type: command.Int, Description: "Patch line budget spread across files: small files are shown whole, large ones are cut at hunk boundaries or summarised, and every cut file is listed in truncated_files. Replaces max_patch_lines."},
+			{Name: "exclude", Type: command.Array, Description: "Glob patterns for files to summarise instead of showing their patch (e.g. ['*.lock', 'testdata/*']); patterns without a slash match the file name in any directory"},
 		},
 		MapsTools: []command.ToolMapping{
 			{Replaces: "Bash", CommandPrefixes: []string{"git diff"}, UseWhen: "viewing changes"},
@@ -43,20 +49,46 @@ func registerStatusCommands(app *command.App) {
 
 func handleGitStatus(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
 	var params struct {
-		RepoPath string `json:"repo_path"`
+		RepoPath  string   `json:"repo_path"`
+		Untracked string   `json:"untracked"`
+		Ignored   bool     `json:"ignored"`
+		Paths     []string `json:"paths"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
-	out, err := git.Run(ctx, params.RepoPath, "status", "--porcelain=v2", "--branch")
+	gitArgs := []string{"status", "--porcelain=v2", "--branch", "--show-stash", "-z"}
+
+	switch params.Untracked {
+	case "":
+	case "no", "normal", "all":
+		gitArgs = append(gitArgs, "--untracked-files="+params.Untracked)
+	default:
+		return command.TextErrorResult(fmt.Sprintf("invalid untracked %q: must be no, normal, or all", params.Untracked)), nil
+	}
+
+	if params.Ignored {
+		gitArgs = append(gitArgs, "--ignored")
+	}
+
+	if len(params.Paths) > 0 {
+		gitArgs = append(gitArgs, "--")
+		gitArgs = append(gitArgs, params.Paths...)
+	}
+
+	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git status: %v", err)), nil
+		return gitErrorResult("git status", err), nil
 	}
 
 	result := git.ParseStatus(out)
 
+	if gitDir, err := git.ResolveGitDir(params.RepoPath); err == nil {
+		result.InProgress = git.DetectInProgress(gitDir)
+	}
+
 	return command.JSONResult(result), nil
 }
 
@@ -69,12 +101,19 @@ func handleGitDiff(ctx context.Context, args json.RawMessage, _ command.Prompter
 		StatOnly      bool     `json:"stat_only"`
 		ContextLines  *int     `json:"context_lines"`
 		MaxPatchLines int      `json:"max_patch_lines"`
+		Format        string   `json:"format"`
+		BudgetLines   int      `json:"budget_lines"`
+		Exclude       []string `json:"exclude"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
 		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
 	}
 
+	if err := validatePatchOptions(params.Format, params.MaxPatchLines, params.BudgetLines); err != nil {
+		return command.TextErrorResult(err.Error()), nil
+	}
+
 	numstatArgs := []string{"diff", "--numstat"}
 	if params.Staged {
 		numstatArgs = append(numstatArgs, "--cached")
@@ -89,7 +128,7 @@ func handleGitDiff(ctx context.Context, args json.RawMessage, _ command.Prompter
 
 	numstatOut, err := git.Run(ctx, params.RepoPath, numstatArgs...)
 	if err != nil {
-		return command.TextErrorResult(fmt.Sprintf("git diff: %v", err)), nil
+		return gitErrorResult("git diff", err), nil
 	}
 
 	stats := git.ParseDiffNumstat(numstatOut)
@@ -124,14 +163,70 @@ func handleGitDiff(ctx context.Context, args json.RawMessage, _ command.Prompter
 
 		patchOut, err := git.Run(ctx, params.RepoPath, patchArgs...)
 		if err != nil {
-			return command.TextErrorResult(fmt.Sprintf("git diff: %v", err)), nil
+			return gitErrorResult("git diff", err), nil
 		}
 
-		patch, truncated, truncatedAt := git.TruncatePatch(patchOut, params.MaxPatchLines)
-		result.Patch = patch
-		result.Truncated = truncated
-		result.TruncatedAtLine = truncatedAt
+		rendered := renderPatch(patchOut, params.Format, params.MaxPatchLines, params.BudgetLines, params.Exclude)
+		result.Patch = rendered.patch
+		result.Files = rendered.files
+		result.Truncated = rendered.truncated
+		result.TruncatedAtLine = rendered.truncatedAtLine
+		result.TruncatedFiles = rendered.truncatedFiles
 	}
 
 	return command.JSONResult(result), nil
 }
+
+func validatePatchOptions(format string, maxPatchLines, budgetLines int) error {
+	switch format {
+	case "", "patch", "structured":
+	default:
+		return fmt.Errorf("invalid format %q: must be patch or structured", format)
+	}
+
+	if maxPatchLines > 0 && budgetLines > 0 {
+		return fmt.Errorf("max_patch_lines and budget_lines cannot both be specified")
+	}
+
+	return nil
+}
+
+// renderedPatch is a patch in the format and size the caller asked for.
+type renderedPatch struct {
+	patch           string
+	files           []git.PatchFile
+	truncated       bool
+	truncatedAtLine int
+	truncatedFiles  []git.FileTruncation
+}
+
+// renderPatch formats patchOut for diff and show. A budget or exclude list
+// selects budgeted truncation, which spreads lines across files; otherwise
+// the patch is cut after maxPatchLines.
+func renderPatch(patchOut, format string, maxPatchLines, budgetLines int, exclude []string) renderedPatch {
+	var r renderedPatch
+
+	if budgetLines > 0 || len(exclude) > 0 {
+		if format == "structured" {
+			r.files, r.truncatedFiles = git.BudgetFiles(git.ParsePatch(patchOut), budgetLines, exclude)
+		} else {
+			r.patch, r.truncatedFiles = git.BudgetPatch(patchOut, budgetLines, exclude)
+		}
+
+		for _, tr := range r.truncatedFiles {
+			if tr.Status != "excluded" {
+				r.truncated = true
+			}
+		}
+
+		return r
+	}
+
+	if format == "structured" {
+		r.files, r.truncated, r.truncatedAtLine = git.TruncateFiles(git.ParsePatch(patchOut), maxPatchLines)
+	} else {
+		r.patch, r.truncated, r.truncatedAtLine = git.TruncatePatch(patchOut, maxPatchLines)
+	}
+
+	return r
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/friedenberg/grit/internal/git"
+	"github.com/friedenberg/grit/internal/git/gittest"
+)
+
+func TestStatusReportsConflicts(t *testing.T) {
+	runner := gittest.NewFakeRunner(t)
+	runner.On("status").Return("# branch.oid abc123\x00# branch.head main\x00" +
+		"u UU N... 100644 100644 100644 100644 aaa bbb ccc a file.txt\x00")
+
+	var result git.StatusResult
+	decodeResult(t, runTool(t, runner, "status", `{"repo_path":"/repo"}`), &result)
+
+	if len(result.Entries) != 1 || result.Entries[0].Conflict != "both_modified" || result.Entries[0].Path != "a file.txt" {
+		t.Errorf("entries = %+v, want one both_modified conflict", result.Entries)
+	}
+
+	if args := runner.Calls()[0].Args; !slices.Contains(args, "-z") {
+		t.Errorf("args = %v, want -z", args)
+	}
+}
+
+func TestStatusOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		wantTail []string
+	}{
+		{"defaults", `{"repo_path":"/repo"}`, []string{"--show-stash", "-z"}},
+		{"untracked all", `{"repo_path":"/repo","untracked":"all"}`, []string{"-z", "--untracked-files=all"}},
+		{"ignored", `{"repo_path":"/repo","ignored":true}`, []string{"-z", "--ignored"}},
+		{"paths", `{"repo_path":"/repo","untracked":"no","paths":["src","a b"]}`, []string{"--untracked-files=no", "--", "src", "a b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := gittest.NewFakeRunner(t)
+			runner.On("status").Return("# branch.oid abc123\x00# branch.head main\x00")
+
+			var result git.StatusResult
+			decodeResult(t, runTool(t, runner, "status", tt.args), &result)
+
+			args := runner.Calls()[0].Args
+			if !slices.Equal(args[len(args)-len(tt.wantTail):], tt.wantTail) {
+				t.Errorf("args = %v, want ending %v", args, tt.wantTail)
+			}
+		})
+	}
+}
+
+func TestStatusInvalidUntracked(t *testing.T) {
+	result := runTool(t, gittest.NewFakeRunner(t), "status", `{"repo_path":"/repo","untracked":"some"}`)
+
+	if !result.IsErr {
+		t.Errorf("result = %+v, want error for invalid untracked mode", result)
+	}
+}
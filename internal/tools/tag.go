@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+var tagSortKeys = map[string]string{
+	"name":        "refname",
+	"version":     "version:refname",
+	"creatordate": "creatordate",
+}
+
+func registerTagCommands(app *command.App) {
+	app.AddCommand(&command.Command{
+		Name:        "tag_list",
+		Description: command.Description{Short: "List tags with annotated tag metadata"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "pattern", Type: command.String, Description: "Only list tags matching this glob (e.g. 'v1.*')"},
+			{Name: "sort", Type: command.String, Description: "Sort order: name (default), version, or creatordate. Prefix with '-' for descending (e.g. '-version')"},
+			{Name: "limit", Type: command.Int, Description: "Maximum number of tags per page (default 100)"},
+			{Name: "cursor", Type: command.String, Description: "next_cursor from a previous call, to fetch the following page; pass the same other arguments"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git tag -l", "git tag --list"}, UseWhen: "listing tags"},
+		},
+		Run: handleGitTagList,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "tag_create",
+		Description: command.Description{Short: "Create a tag (annotated when a message is given, lightweight otherwise)"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "name", Type: command.String, Description: "Name of the tag", Required: true},
+			{Name: "ref", Type: command.String, Description: "Commit or ref to tag (defaults to HEAD)"},
+			{Name: "message", Type: command.String, Description: "Tag message; creates an annotated tag (-a) when set"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git tag -a", "git tag"}, UseWhen: "creating a tag"},
+		},
+		Run: handleGitTagCreate,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "tag_delete",
+		Description: command.Description{Short: "Delete a local tag"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "name", Type: command.String, Description: "Name of the tag to delete", Required: true},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git tag -d", "git tag --delete"}, UseWhen: "deleting a tag"},
+		},
+		Run: handleGitTagDelete,
+	})
+}
+
+func handleGitTagList(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Pattern  string `json:"pattern"`
+		Sort     string `json:"sort"`
+		Limit    int    `json:"limit"`
+		Cursor   string `json:"cursor"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	cur, err := decodeCursor("tag_list", params.Cursor)
+	if err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid cursor: %v", err)), nil
+	}
+
+	gitArgs := []string{"tag", "--list", fmt.Sprintf("--format=%s", git.TagListFormat)}
+
+	if params.Sort != "" {
+		descending := strings.HasPrefix(params.Sort, "-")
+		key, ok := tagSortKeys[strings.TrimPrefix(params.Sort, "-")]
+		if !ok {
+			return command.TextErrorResult(fmt.Sprintf("invalid sort %q: must be name, version, or creatordate", params.Sort)), nil
+		}
+
+		if descending {
+			key = "-" + key
+		}
+
+		gitArgs = append(gitArgs, "--sort="+key)
+	}
+
+	if params.Pattern != "" {
+		gitArgs = append(gitArgs, params.Pattern)
+	}
+
+	out, err := git.Run(ctx, params.RepoPath, gitArgs...)
+	if err != nil {
+		return gitErrorResult("git tag", err), nil
+	}
+
+	var result git.TagListResult
+	result.Tags, result.NextCursor = pageAfter(git.ParseTagList(out), cur, pageSize(params.Limit), func(t git.TagEntry) string {
+		return t.Name
+	})
+
+	return command.JSONResult(result), nil
+}
+
+func handleGitTagCreate(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Name     string `json:"name"`
+		Ref      string `json:"ref"`
+		Message  string `json:"message"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	annotated := params.Message != ""
+
+	gitArgs := []string{"tag"}
+
+	if annotated {
+		gitArgs = append(gitArgs, "--annotate", "--message", params.Message)
+	}
+
+	gitArgs = append(gitArgs, "--", params.Name)
+
+	if params.Ref != "" {
+		gitArgs = append(gitArgs, params.Ref)
+	}
+
+	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
+		return gitErrorResult("git tag", err), nil
+	}
+
+	result := git.TagResult{
+		Status:    "created",
+		Name:      params.Name,
+		Ref:       params.Ref,
+		Annotated: annotated,
+	}
+
+	hashOut, err := git.Run(ctx, params.RepoPath, "rev-parse", "--verify", "refs/tags/"+params.Name+"^{commit}")
+	if err == nil {
+		result.Hash = strings.TrimSpace(hashOut)
+	}
+
+	return command.JSONResult(result), nil
+}
+
+func handleGitTagDelete(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Name     string `json:"name"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	if _, err := git.Run(ctx, params.RepoPath, "tag", "--delete", "--", params.Name); err != nil {
+		return gitErrorResult("git tag --delete", err), nil
+	}
+
+	return command.JSONResult(git.TagResult{
+		Status: "deleted",
+		Name:   params.Name,
+	}), nil
+}
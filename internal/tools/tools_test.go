@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// runTool calls the named tool the way the MCP server does, with git run
+// through runner.
+func runTool(t *testing.T, runner git.Runner, name, args string) *command.Result {
+	t.Helper()
+
+	cmd, ok := RegisterAll(runner, Timeouts{}).GetCommand(name)
+	if !ok {
+		t.Fatalf("unknown tool %s", name)
+	}
+
+	result, err := cmd.Run(context.Background(), json.RawMessage(args), command.StubPrompter{})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	return result
+}
+
+// decodeResult unmarshals a JSON result into v, failing on error results.
+func decodeResult(t *testing.T, result *command.Result, v any) {
+	t.Helper()
+
+	if result.IsErr {
+		t.Fatalf("unexpected error result: %s", result.Text)
+	}
+
+	data, err := json.Marshal(result.JSON)
+	if err != nil {
+		t.Fatalf("marshaling result: %v", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("unmarshaling result: %v", err)
+	}
+}
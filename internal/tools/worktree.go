@@ -0,0 +1,319 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/command"
+	"github.com/friedenberg/grit/internal/git"
+)
+
+// worktreeRootEnv overrides the directory new worktrees are created under.
+// When unset, worktrees go in a "<repo>-worktrees" directory next to the
+// main worktree.
+const worktreeRootEnv = "GRIT_WORKTREE_ROOT"
+
+func registerWorktreeCommands(app *command.App) {
+	app.AddCommand(&command.Command{
+		Name:        "worktree_list",
+		Description: command.Description{Short: "List worktrees attached to the repository"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git worktree list"}, UseWhen: "listing worktrees"},
+		},
+		Run: handleGitWorktreeList,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "worktree_add",
+		Description: command.Description{Short: "Create a worktree under the worktree root (" + worktreeRootEnv + ", default <repo>-worktrees)"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "path", Type: command.String, Description: "Worktree path relative to the worktree root (defaults to the branch name)"},
+			{Name: "branch", Type: command.String, Description: "Existing branch to check out in the new worktree"},
+			{Name: "new_branch", Type: command.String, Description: "Create a new branch with this name and check it out (-b)"},
+			{Name: "start_point", Type: command.String, Description: "Commit to start the new branch or detached HEAD at (defaults to HEAD)"},
+			{Name: "detach", Type: command.Bool, Description: "Check out start_point with a detached HEAD"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git worktree add"}, UseWhen: "creating a worktree"},
+		},
+		Run: handleGitWorktreeAdd,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "worktree_remove",
+		Description: command.Description{Short: "Remove a worktree (refused when it has uncommitted changes unless forced)"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "path", Type: command.String, Description: "Worktree path, absolute or relative to the worktree root", Required: true},
+			{Name: "force", Type: command.Bool, Description: "Remove even if the worktree has uncommitted or untracked changes"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git worktree remove"}, UseWhen: "removing a worktree"},
+		},
+		Run: handleGitWorktreeRemove,
+	})
+
+	app.AddCommand(&command.Command{
+		Name:        "worktree_prune",
+		Description: command.Description{Short: "Prune administrative data for worktrees whose directories are gone"},
+		Params: []command.Param{
+			{Name: "repo_path", Type: command.String, Description: "Path to the git repository", Required: true},
+			{Name: "dry_run", Type: command.Bool, Description: "Report what would be pruned without removing anything"},
+		},
+		MapsTools: []command.ToolMapping{
+			{Replaces: "Bash", CommandPrefixes: []string{"git worktree prune"}, UseWhen: "pruning stale worktrees"},
+		},
+		Run: handleGitWorktreePrune,
+	})
+}
+
+func listWorktrees(ctx context.Context, repoPath string) ([]git.WorktreeEntry, error) {
+	out, err := git.Run(ctx, repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	return git.ParseWorktreeList(out), nil
+}
+
+// worktreeRoot returns the directory new worktrees are created under.
+func worktreeRoot(ctx context.Context, repoPath string) (string, error) {
+	if root := os.Getenv(worktreeRootEnv); root != "" {
+		return filepath.Abs(root)
+	}
+
+	worktrees, err := listWorktrees(ctx, repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	if len(worktrees) == 0 {
+		return "", fmt.Errorf("no main worktree found")
+	}
+
+	// The first entry is always the main worktree.
+	mainPath := strings.TrimSuffix(worktrees[0].Path, ".git")
+	return filepath.Join(filepath.Dir(mainPath), filepath.Base(mainPath)+"-worktrees"), nil
+}
+
+// resolveWorktreePath resolves path against the worktree root and ensures
+// the result does not escape it.
+func resolveWorktreePath(root, path string) (string, error) {
+	resolved := path
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(root, resolved)
+	}
+	resolved = filepath.Clean(resolved)
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("worktree path must be inside the worktree root %s", root)
+	}
+
+	return resolved, nil
+}
+
+func handleGitWorktreeList(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	worktrees, err := listWorktrees(ctx, params.RepoPath)
+	if err != nil {
+		return gitErrorResult("git worktree list", err), nil
+	}
+
+	return command.JSONResult(worktrees), nil
+}
+
+func handleGitWorktreeAdd(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath   string `json:"repo_path"`
+		Path       string `json:"path"`
+		Branch     string `json:"branch"`
+		NewBranch  string `json:"new_branch"`
+		StartPoint string `json:"start_point"`
+		Detach     bool   `json:"detach"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	modeCount := 0
+	if params.Branch != "" {
+		modeCount++
+	}
+	if params.NewBranch != "" {
+		modeCount++
+	}
+	if params.Detach {
+		modeCount++
+	}
+
+	if modeCount != 1 {
+		return command.TextErrorResult("exactly one of branch, new_branch, or detach must be specified"), nil
+	}
+
+	if params.Branch != "" && params.StartPoint != "" {
+		return command.TextErrorResult("start_point cannot be used with an existing branch"), nil
+	}
+
+	path := params.Path
+	if path == "" {
+		name := params.Branch
+		if name == "" {
+			name = params.NewBranch
+		}
+		if name == "" {
+			return command.TextErrorResult("path is required for a detached worktree"), nil
+		}
+		path = strings.ReplaceAll(name, "/", "-")
+	}
+
+	root, err := worktreeRoot(ctx, params.RepoPath)
+	if err != nil {
+		return gitErrorResult("resolving worktree root", err), nil
+	}
+
+	worktreePath, err := resolveWorktreePath(root, path)
+	if err != nil {
+		return command.TextErrorResult(err.Error()), nil
+	}
+
+	gitArgs := []string{"worktree", "add"}
+
+	switch {
+	case params.NewBranch != "":
+		gitArgs = append(gitArgs, "-b", params.NewBranch, worktreePath)
+	case params.Detach:
+		gitArgs = append(gitArgs, "--detach", worktreePath)
+	default:
+		gitArgs = append(gitArgs, worktreePath, params.Branch)
+	}
+
+	if params.StartPoint != "" {
+		gitArgs = append(gitArgs, params.StartPoint)
+	}
+
+	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
+		return gitErrorResult("git worktree add", err), nil
+	}
+
+	branch := params.Branch
+	if params.NewBranch != "" {
+		branch = params.NewBranch
+	}
+
+	return command.JSONResult(git.WorktreeResult{
+		Status: "added",
+		Path:   worktreePath,
+		Branch: branch,
+		Head:   resolveHead(ctx, worktreePath),
+	}), nil
+}
+
+func handleGitWorktreeRemove(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		Path     string `json:"path"`
+		Force    bool   `json:"force"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	worktreePath := params.Path
+	if !filepath.IsAbs(worktreePath) {
+		root, err := worktreeRoot(ctx, params.RepoPath)
+		if err != nil {
+			return gitErrorResult("resolving worktree root", err), nil
+		}
+		worktreePath = filepath.Join(root, worktreePath)
+	}
+
+	if !params.Force {
+		out, err := git.Run(ctx, worktreePath, "status", "--porcelain=v2", "-z")
+		if err != nil {
+			return gitErrorResult("git status", err), nil
+		}
+
+		status := git.ParseStatus(out)
+		if len(status.Entries) > 0 {
+			dirty := make([]string, 0, len(status.Entries))
+			for _, entry := range status.Entries {
+				dirty = append(dirty, entry.Path)
+			}
+			return command.TextErrorResult(fmt.Sprintf("worktree has uncommitted changes (%s); use force to remove anyway", strings.Join(dirty, ", "))), nil
+		}
+	}
+
+	gitArgs := []string{"worktree", "remove"}
+
+	if params.Force {
+		gitArgs = append(gitArgs, "--force")
+	}
+
+	gitArgs = append(gitArgs, worktreePath)
+
+	if _, err := git.Run(ctx, params.RepoPath, gitArgs...); err != nil {
+		return gitErrorResult("git worktree remove", err), nil
+	}
+
+	return command.JSONResult(git.WorktreeResult{
+		Status: "removed",
+		Path:   worktreePath,
+	}), nil
+}
+
+func handleGitWorktreePrune(ctx context.Context, args json.RawMessage, _ command.Prompter) (*command.Result, error) {
+	var params struct {
+		RepoPath string `json:"repo_path"`
+		DryRun   bool   `json:"dry_run"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return command.TextErrorResult(fmt.Sprintf("invalid arguments: %v", err)), nil
+	}
+
+	// git worktree prune only reports what it removed on stderr, so work out
+	// the prunable entries from the porcelain listing beforehand.
+	worktrees, err := listWorktrees(ctx, params.RepoPath)
+	if err != nil {
+		return gitErrorResult("git worktree list", err), nil
+	}
+
+	var prunable []git.WorktreeEntry
+	for _, wt := range worktrees {
+		if wt.Prunable && !wt.Locked {
+			prunable = append(prunable, wt)
+		}
+	}
+
+	status := "would_prune"
+
+	if !params.DryRun {
+		if _, err := git.Run(ctx, params.RepoPath, "worktree", "prune"); err != nil {
+			return gitErrorResult("git worktree prune", err), nil
+		}
+		status = "pruned"
+	}
+
+	return command.JSONResult(git.WorktreeResult{
+		Status: status,
+		Pruned: prunable,
+	}), nil
+}
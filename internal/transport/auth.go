@@ -0,0 +1,92 @@
+package transport
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// LoadToken reads a bearer token from the file at path, or from the
+// environment variable env if path is empty. Surrounding whitespace is
+// ignored. Returns an empty token if neither is set.
+func LoadToken(path, env string) (string, error) {
+	if path == "" {
+		return strings.TrimSpace(os.Getenv(env)), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+
+	return token, nil
+}
+
+// guard wraps next with the Origin and bearer token checks. Disallowed
+// origins get 403 and missing or wrong tokens get 401; the origin is
+// checked first so DNS-rebinding pages learn nothing about the token.
+func (o HTTPOptions) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !o.originAllowed(origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		if o.Token != "" && !o.tokenValid(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="grit"`)
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (o HTTPOptions) originAllowed(origin string) bool {
+	if len(o.AllowedOrigins) == 0 {
+		return isLoopbackOrigin(origin)
+	}
+
+	for _, allowed := range o.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o HTTPOptions) tokenValid(r *http.Request) bool {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(o.Token)) == 1
+}
+
+// isLoopbackOrigin reports whether origin is a page served from this
+// machine, such as http://localhost:3000 or http://127.0.0.1.
+func isLoopbackOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
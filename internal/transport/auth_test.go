@@ -0,0 +1,221 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	intTransport "github.com/friedenberg/grit/internal/transport"
+)
+
+// startGuardedSSE starts an SSE transport with the given options. Sessions
+// stay open until the client disconnects.
+func startGuardedSSE(t *testing.T, opts intTransport.HTTPOptions) *intTransport.SSE {
+	t.Helper()
+	s := intTransport.NewSSE(":0", func(ctx context.Context, session intTransport.SessionTransport) {
+		<-ctx.Done()
+	}, opts)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("starting SSE: %v", err)
+	}
+	t.Cleanup(func() {
+		http.DefaultClient.CloseIdleConnections()
+		s.Close()
+	})
+	return s
+}
+
+// getSSE requests /sse with the given headers.
+func getSSE(t *testing.T, s *intTransport.SSE, headers map[string]string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, baseURL(s)+"/sse", nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /sse: %v", err)
+	}
+
+	return resp
+}
+
+func TestBearerToken(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{Token: "s3cret"})
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", http.StatusUnauthorized},
+		{"wrong scheme", "Basic s3cret", http.StatusUnauthorized},
+		{"valid", "Bearer s3cret", http.StatusOK},
+		{"lowercase scheme", "bearer s3cret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.header != "" {
+				headers["Authorization"] = tt.header
+			}
+
+			resp := getSSE(t, s, headers)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, resp.StatusCode)
+			}
+
+			if tt.want == http.StatusUnauthorized && !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Bearer") {
+				t.Fatalf("expected Bearer challenge, got %q", resp.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestBearerTokenOnMessage(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{Token: "s3cret"})
+
+	resp, err := http.Post(baseURL(s)+"/message?sessionId=x", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("POST /message: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestDefaultOriginsLoopbackOnly(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{})
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusOK},
+		{"http://localhost:3000", http.StatusOK},
+		{"http://127.0.0.1", http.StatusOK},
+		{"http://[::1]:8080", http.StatusOK},
+		{"http://evil.example", http.StatusForbidden},
+		{"http://localhost.evil.example", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.origin != "" {
+				headers["Origin"] = tt.origin
+			}
+
+			resp := getSSE(t, s, headers)
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{AllowedOrigins: []string{"https://app.example/"}})
+
+	resp := getSSE(t, s, map[string]string{"Origin": "https://app.example"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("allowed origin: expected 200, got %d", resp.StatusCode)
+	}
+
+	// An explicit allowlist replaces the loopback default.
+	resp = getSSE(t, s, map[string]string{"Origin": "http://localhost:3000"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unlisted origin: expected 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestOriginCheckedBeforeToken(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{Token: "s3cret"})
+
+	resp := getSSE(t, s, map[string]string{"Origin": "http://evil.example"})
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableRequiresToken(t *testing.T) {
+	s := intTransport.NewStreamableHTTP(":0", nil, intTransport.HTTPOptions{Token: "s3cret"})
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("starting Streamable HTTP: %v", err)
+	}
+	t.Cleanup(func() {
+		http.DefaultClient.CloseIdleConnections()
+		s.Close()
+	})
+
+	resp := postMCP(t, s, "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+
+	if n := s.SessionCount(); n != 0 {
+		t.Fatalf("expected no sessions, got %d", n)
+	}
+}
+
+func TestLoadToken(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("  from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GRIT_TEST_TOKEN", " from-env ")
+
+	token, err := intTransport.LoadToken(path, "GRIT_TEST_TOKEN")
+	if err != nil {
+		t.Fatalf("LoadToken: %v", err)
+	}
+	if token != "from-file" {
+		t.Errorf("token = %q, want %q", token, "from-file")
+	}
+
+	token, err = intTransport.LoadToken("", "GRIT_TEST_TOKEN")
+	if err != nil {
+		t.Fatalf("LoadToken: %v", err)
+	}
+	if token != "from-env" {
+		t.Errorf("token = %q, want %q", token, "from-env")
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := intTransport.LoadToken(empty, "GRIT_TEST_TOKEN"); err == nil {
+		t.Error("expected error for empty token file")
+	}
+
+	if _, err := intTransport.LoadToken(filepath.Join(dir, "missing"), "GRIT_TEST_TOKEN"); err == nil {
+		t.Error("expected error for missing token file")
+	}
+}
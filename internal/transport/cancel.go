@@ -0,0 +1,118 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/jsonrpc"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/protocol"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/server"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/transport"
+)
+
+// MethodCancelled is the notification a client sends to abandon a request.
+const MethodCancelled = "notifications/cancelled"
+
+// Cancellable wraps t so each tools/call runs with its own context, which a
+// notifications/cancelled naming the request cancels. The server hands
+// every handler the same context, so tool calls are answered here instead
+// of being passed on; every other message reaches the server unchanged.
+// Calls run under ctx, and Close waits for them before closing t.
+func Cancellable(ctx context.Context, t transport.Transport, tools server.ToolProvider) transport.Transport {
+	return &cancellable{
+		Transport: t,
+		ctx:       ctx,
+		tools:     tools,
+		inflight:  make(map[string]context.CancelFunc),
+	}
+}
+
+type cancellable struct {
+	transport.Transport
+	ctx   context.Context
+	tools server.ToolProvider
+
+	mu       sync.Mutex
+	inflight map[string]context.CancelFunc
+	calls    sync.WaitGroup
+}
+
+func (c *cancellable) Read() (*jsonrpc.Message, error) {
+	for {
+		msg, err := c.Transport.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case msg.IsRequest() && msg.Method == protocol.MethodToolsCall:
+			c.call(msg)
+		case msg.IsNotification() && msg.Method == MethodCancelled:
+			c.cancel(msg)
+		default:
+			return msg, nil
+		}
+	}
+}
+
+func (c *cancellable) call(msg *jsonrpc.Message) {
+	id := *msg.ID
+
+	var params protocol.ToolCallParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		resp, _ := jsonrpc.NewErrorResponse(id, jsonrpc.InvalidParams, "invalid params", nil)
+		c.Transport.Write(resp)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	key := idKey(&id)
+
+	c.mu.Lock()
+	c.inflight[key] = cancel
+	c.mu.Unlock()
+
+	c.calls.Add(1)
+	go func() {
+		defer c.calls.Done()
+		defer func() {
+			c.mu.Lock()
+			delete(c.inflight, key)
+			c.mu.Unlock()
+			cancel()
+		}()
+
+		var resp *jsonrpc.Message
+		result, err := c.tools.CallTool(ctx, params.Name, params.Arguments)
+		if err != nil {
+			resp, _ = jsonrpc.NewErrorResponse(id, jsonrpc.InternalError, err.Error(), nil)
+		} else {
+			resp, _ = jsonrpc.NewResponse(id, result)
+		}
+
+		c.Transport.Write(resp)
+	}()
+}
+
+func (c *cancellable) cancel(msg *jsonrpc.Message) {
+	var params struct {
+		RequestID jsonrpc.ID `json:"requestId"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	cancel := c.inflight[idKey(&params.RequestID)]
+	c.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (c *cancellable) Close() error {
+	c.calls.Wait()
+	return c.Transport.Close()
+}
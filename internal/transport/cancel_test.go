@@ -0,0 +1,113 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/jsonrpc"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/protocol"
+	intTransport "github.com/friedenberg/grit/internal/transport"
+)
+
+// pipeTransport feeds messages from in and collects writes on out.
+type pipeTransport struct {
+	in  chan *jsonrpc.Message
+	out chan *jsonrpc.Message
+}
+
+func newPipeTransport() *pipeTransport {
+	return &pipeTransport{in: make(chan *jsonrpc.Message, 8), out: make(chan *jsonrpc.Message, 8)}
+}
+
+func (p *pipeTransport) Read() (*jsonrpc.Message, error) {
+	msg, ok := <-p.in
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func (p *pipeTransport) Write(msg *jsonrpc.Message) error {
+	p.out <- msg
+	return nil
+}
+
+func (p *pipeTransport) Close() error { return nil }
+
+// blockingTools answers every call once its context ends, reporting why.
+type blockingTools struct{}
+
+func (blockingTools) ListTools(ctx context.Context) ([]protocol.Tool, error) { return nil, nil }
+
+func (blockingTools) CallTool(ctx context.Context, name string, args json.RawMessage) (*protocol.ToolCallResult, error) {
+	<-ctx.Done()
+	return protocol.ErrorResult(ctx.Err().Error()), nil
+}
+
+func TestCancellableCancelsNamedCall(t *testing.T) {
+	pipe := newPipeTransport()
+	c := intTransport.Cancellable(context.Background(), pipe, blockingTools{})
+
+	call, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(7), protocol.MethodToolsCall, protocol.ToolCallParams{Name: "fetch"})
+	cancel, _ := jsonrpc.NewNotification(intTransport.MethodCancelled, map[string]any{"requestId": 7, "reason": "user"})
+	ping, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(8), protocol.MethodPing, nil)
+	pipe.in <- call
+	pipe.in <- cancel
+	pipe.in <- ping
+
+	// Only the ping reaches the server; the call and the cancellation are
+	// handled by the wrapper.
+	msg, err := c.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if msg.Method != protocol.MethodPing {
+		t.Fatalf("Read = %s, want ping", msg.Method)
+	}
+
+	select {
+	case resp := <-pipe.out:
+		if resp.ID.String() != jsonrpc.NewNumberID(7).String() {
+			t.Fatalf("response ID = %s, want 7", resp.ID)
+		}
+
+		var result protocol.ToolCallResult
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			t.Fatalf("unmarshaling result: %v", err)
+		}
+		if !result.IsError || result.Content[0].Text != context.Canceled.Error() {
+			t.Errorf("result = %+v, want cancelled", result)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call was not cancelled")
+	}
+
+	close(pipe.in)
+	c.Close()
+}
+
+func TestCancellableCloseCancelsWithContext(t *testing.T) {
+	pipe := newPipeTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	c := intTransport.Cancellable(ctx, pipe, blockingTools{})
+
+	call, _ := jsonrpc.NewRequest(jsonrpc.NewStringID("a"), protocol.MethodToolsCall, protocol.ToolCallParams{Name: "status"})
+	pipe.in <- call
+	close(pipe.in)
+
+	if _, err := c.Read(); err != io.EOF {
+		t.Fatalf("Read = %v, want EOF", err)
+	}
+
+	cancel()
+	c.Close()
+
+	select {
+	case <-pipe.out:
+	default:
+		t.Fatal("Close returned before the in-flight call answered")
+	}
+}
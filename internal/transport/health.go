@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds how long a readiness check may take.
+const readyTimeout = 5 * time.Second
+
+// routes serves the transport's MCP endpoints behind the guard, plus the
+// operational endpoints: GET /healthz, GET /readyz, and GET /metrics.
+// Health and readiness reveal nothing about the repositories, so probes
+// can reach them without a token; metrics are guarded like MCP traffic.
+func (o HTTPOptions) routes(mcp http.Handler, sessions func() int) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if o.Ready != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+			defer cancel()
+
+			if err := o.Ready(ctx); err != nil {
+				http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.Handle("GET /metrics", o.guard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if o.Metrics == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		o.Metrics(w, sessions())
+	})))
+
+	mux.Handle("/", o.guard(mcp))
+
+	return mux
+}
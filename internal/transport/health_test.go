@@ -0,0 +1,102 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	intTransport "github.com/friedenberg/grit/internal/transport"
+)
+
+func getBody(t *testing.T, url string, headers map[string]string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestHealthz(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{Token: "s3cret"})
+
+	// Probes do not need the token.
+	code, body := getBody(t, baseURL(s)+"/healthz", nil)
+	if code != http.StatusOK || strings.TrimSpace(body) != "ok" {
+		t.Fatalf("expected 200 ok, got %d %q", code, body)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	var readyErr error
+	s := startGuardedSSE(t, intTransport.HTTPOptions{
+		Ready: func(ctx context.Context) error { return readyErr },
+	})
+
+	code, _ := getBody(t, baseURL(s)+"/readyz", nil)
+	if code != http.StatusOK {
+		t.Fatalf("ready: expected 200, got %d", code)
+	}
+
+	readyErr = errors.New("git not found on PATH")
+
+	code, body := getBody(t, baseURL(s)+"/readyz", nil)
+	if code != http.StatusServiceUnavailable {
+		t.Fatalf("not ready: expected 503, got %d", code)
+	}
+	if !strings.Contains(body, "git not found on PATH") {
+		t.Fatalf("expected reason in body, got %q", body)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{
+		Token: "s3cret",
+		Metrics: func(w io.Writer, activeSessions int) error {
+			_, err := fmt.Fprintf(w, "grit_active_sessions %d\n", activeSessions)
+			return err
+		},
+	})
+
+	code, _ := getBody(t, baseURL(s)+"/metrics", nil)
+	if code != http.StatusUnauthorized {
+		t.Fatalf("without token: expected 401, got %d", code)
+	}
+
+	resp := getSSE(t, s, map[string]string{"Authorization": "Bearer s3cret"})
+	defer resp.Body.Close()
+	readEvent(t, resp.Body, "endpoint")
+
+	code, body := getBody(t, baseURL(s)+"/metrics", map[string]string{"Authorization": "Bearer s3cret"})
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if body != "grit_active_sessions 1\n" {
+		t.Fatalf("unexpected metrics body %q", body)
+	}
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	s := startGuardedSSE(t, intTransport.HTTPOptions{})
+
+	code, _ := getBody(t, baseURL(s)+"/metrics", nil)
+	if code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", code)
+	}
+}
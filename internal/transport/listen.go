@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// unixPrefix marks a listen address as a Unix domain socket path.
+const unixPrefix = "unix:"
+
+// listen opens the listener for an HTTP transport. Addresses of the form
+// "unix:/path/to/socket" listen on a Unix domain socket that only the
+// current user can connect to, which is removed again when the listener
+// closes. Anything else is a TCP address.
+func listen(addr string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(addr, unixPrefix)
+	if !ok {
+		return net.Listen("tcp", addr)
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("missing socket path")
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	return listenUnix(path)
+}
+
+// removeStaleSocket removes a socket left behind at path by a process that
+// exited without cleaning up. A socket that still accepts connections, or
+// a file that is not a socket, is left alone and reported as an error.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another server", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing stale socket: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package transport_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	intTransport "github.com/friedenberg/grit/internal/transport"
+)
+
+// unixClient returns an HTTP client that connects to the socket at path.
+func unixClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// socketPath returns a socket path short enough for sun_path limits.
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "grit-sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "grit.sock")
+}
+
+func startUnixSSE(t *testing.T, path string) (*intTransport.SSE, error) {
+	t.Helper()
+	s := intTransport.NewSSE("unix:"+path, func(ctx context.Context, session intTransport.SessionTransport) {
+		<-ctx.Done()
+	}, intTransport.HTTPOptions{})
+	return s, s.Start(context.Background())
+}
+
+func TestUnixSocketListener(t *testing.T) {
+	path := socketPath(t)
+
+	s, err := startUnixSSE(t, path)
+	if err != nil {
+		t.Fatalf("starting SSE: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o, want 600", perm)
+	}
+
+	if s.Addr().Network() != "unix" {
+		t.Errorf("Addr().Network() = %q, want unix", s.Addr().Network())
+	}
+
+	client := unixClient(path)
+	resp, err := client.Get("http://grit/sse")
+	if err != nil {
+		t.Fatalf("GET /sse over socket: %v", err)
+	}
+
+	endpoint := readEvent(t, resp.Body, "endpoint")
+	resp.Body.Close()
+	if !strings.HasPrefix(endpoint, "/message?sessionId=") {
+		t.Fatalf("unexpected endpoint %q", endpoint)
+	}
+
+	client.CloseIdleConnections()
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Lstat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket to be removed on Close, got %v", err)
+	}
+}
+
+func TestUnixSocketStale(t *testing.T) {
+	path := socketPath(t)
+
+	// Leave a socket file behind with nothing listening on it.
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	s, err := startUnixSSE(t, path)
+	if err != nil {
+		t.Fatalf("expected stale socket to be replaced, got %v", err)
+	}
+	s.Close()
+}
+
+func TestUnixSocketInUse(t *testing.T) {
+	path := socketPath(t)
+
+	first, err := startUnixSSE(t, path)
+	if err != nil {
+		t.Fatalf("starting first SSE: %v", err)
+	}
+	defer first.Close()
+
+	if _, err := startUnixSSE(t, path); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("expected in use error, got %v", err)
+	}
+
+	// The live socket must survive the failed attempt.
+	if _, err := os.Lstat(path); err != nil {
+		t.Fatalf("socket removed by failed start: %v", err)
+	}
+}
+
+func TestUnixSocketNotASocket(t *testing.T) {
+	path := socketPath(t)
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := startUnixSSE(t, path); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("expected not a socket error, got %v", err)
+	}
+
+	if data, err := os.ReadFile(path); err != nil || string(data) != "data" {
+		t.Fatalf("regular file was modified: %q, %v", data, err)
+	}
+}
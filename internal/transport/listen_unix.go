@@ -0,0 +1,19 @@
+//go:build unix
+
+package transport
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnix binds a socket at path that is mode 0600 from the moment it
+// exists. Chmodding it afterwards would leave a window in which any local
+// user could connect, and keep the connection. The umask is process-wide,
+// but listeners are opened at startup before any git process is spawned.
+func listenUnix(path string) (net.Listener, error) {
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+
+	return net.Listen("unix", path)
+}
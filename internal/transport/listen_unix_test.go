@@ -0,0 +1,35 @@
+//go:build unix
+
+package transport_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestUnixSocketCreatedPrivate(t *testing.T) {
+	// With a permissive umask, only the socket being created restrictively
+	// keeps it private; nothing chmods it afterwards.
+	old := syscall.Umask(0)
+	defer syscall.Umask(old)
+
+	path := socketPath(t)
+	s, err := startUnixSSE(t, path)
+	if err != nil {
+		t.Fatalf("starting SSE: %v", err)
+	}
+	defer s.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode = %o right after listening, want 600", perm)
+	}
+
+	if restored := syscall.Umask(0); restored != 0 {
+		t.Errorf("umask = %o after listening, want the caller's 0 restored", restored)
+	}
+}
@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// HTTPOptions configures the HTTP transports.
+type HTTPOptions struct {
+	// Token, if set, is the bearer token every request must present in its
+	// Authorization header.
+	Token string
+
+	// AllowedOrigins lists the Origin header values accepted from browsers,
+	// such as "http://localhost:3000", or "*" for any origin. If empty, only
+	// loopback origins are accepted. Requests without an Origin header are
+	// not from a browser and are always accepted.
+	AllowedOrigins []string
+
+	// Ready, if set, is called by GET /readyz; an error reports the server
+	// as not ready.
+	Ready func(ctx context.Context) error
+
+	// Metrics, if set, writes the Prometheus text served by GET /metrics,
+	// given the transport's number of active sessions.
+	Metrics func(w io.Writer, activeSessions int) error
+
+	// KeepAlive is how often an idle SSE stream gets a comment line so that
+	// proxies do not time it out. Defaults to 15 seconds.
+	KeepAlive time.Duration
+
+	// WriteTimeout bounds each write to an SSE stream. A client that does
+	// not take the data in time is considered dead and its session ends.
+	// Defaults to 10 seconds.
+	WriteTimeout time.Duration
+
+	// SessionIdleTimeout is how long a Streamable HTTP session may go with
+	// no request in flight and no open stream before it is ended, for
+	// clients that go away without sending DELETE. Defaults to 30 minutes.
+	SessionIdleTimeout time.Duration
+
+	// MaxSessions caps the live Streamable HTTP sessions; initialize is
+	// refused with 503 while the cap is reached. Defaults to 100.
+	MaxSessions int
+}
+
+const (
+	defaultKeepAlive          = 15 * time.Second
+	defaultWriteTimeout       = 10 * time.Second
+	defaultSessionIdleTimeout = 30 * time.Minute
+	defaultMaxSessions        = 100
+)
+
+func (o HTTPOptions) keepAlive() time.Duration {
+	if o.KeepAlive <= 0 {
+		return defaultKeepAlive
+	}
+	return o.KeepAlive
+}
+
+func (o HTTPOptions) writeTimeout() time.Duration {
+	if o.WriteTimeout <= 0 {
+		return defaultWriteTimeout
+	}
+	return o.WriteTimeout
+}
+
+func (o HTTPOptions) sessionIdleTimeout() time.Duration {
+	if o.SessionIdleTimeout <= 0 {
+		return defaultSessionIdleTimeout
+	}
+	return o.SessionIdleTimeout
+}
+
+func (o HTTPOptions) maxSessions() int {
+	if o.MaxSessions <= 0 {
+		return defaultMaxSessions
+	}
+	return o.MaxSessions
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	mcptransport "github.com/amarbel-llc/purse-first/libs/go-mcp/transport"
+)
+
+// SessionTransport is one client session of a multi-session transport. Each
+// session is a full go-mcp transport, so it can back its own server.
+type SessionTransport interface {
+	mcptransport.Transport
+
+	// ID returns the identifier clients use to address the session.
+	ID() string
+}
+
+// SessionHandler serves a single session, typically by running an MCP
+// server on it. It is called in its own goroutine when a session starts;
+// ctx is canceled when the client goes away or the transport closes, and
+// the session ends when the handler returns.
+type SessionHandler func(ctx context.Context, session SessionTransport)
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -2,40 +2,43 @@ package transport
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/amarbel-llc/purse-first/libs/go-mcp/jsonrpc"
 )
 
 // SSE implements the MCP SSE transport over HTTP.
-// GET /sse establishes a Server-Sent Events stream for server-to-client messages.
-// POST /message sends client-to-server JSON-RPC messages.
+// GET /sse establishes a Server-Sent Events stream for server-to-client
+// messages and starts a new session. POST /message?sessionId=<id> sends
+// client-to-server JSON-RPC messages to that session.
 type SSE struct {
 	server   *http.Server
 	listener net.Listener
-
-	sessionID  string
-	sseWriter  io.Writer
-	sseFlusher http.Flusher
-	sseReady   chan struct{}
-	incoming   chan *jsonrpc.Message
-	done       chan struct{}
-	closeOnce  sync.Once
-	mu         sync.Mutex
+	handler  SessionHandler
+	opts     HTTPOptions
+
+	sessions  map[string]*Session
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
 }
 
-// NewSSE creates a new SSE transport that listens on the given address.
-func NewSSE(addr string) *SSE {
+// NewSSE creates a new SSE transport that listens on the given address and
+// calls handler for every session. Requests are checked against opts. The
+// address is a TCP host:port or "unix:" followed by a socket path.
+func NewSSE(addr string, handler SessionHandler, opts HTTPOptions) *SSE {
 	return &SSE{
-		sseReady: make(chan struct{}),
-		incoming: make(chan *jsonrpc.Message, 16),
+		handler:  handler,
+		opts:     opts,
+		sessions: make(map[string]*Session),
 		done:     make(chan struct{}),
 		server: &http.Server{
 			Addr: addr,
@@ -54,10 +57,10 @@ func (s *SSE) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /sse", s.handleSSE)
 	mux.HandleFunc("POST /message", s.handleMessage)
-	s.server.Handler = mux
+	s.server.Handler = s.opts.routes(mux, s.SessionCount)
 	s.server.BaseContext = func(_ net.Listener) context.Context { return ctx }
 
-	ln, err := net.Listen("tcp", s.server.Addr)
+	ln, err := listen(s.server.Addr)
 	if err != nil {
 		return fmt.Errorf("listening on %s: %w", s.server.Addr, err)
 	}
@@ -74,69 +77,102 @@ func (s *SSE) Start(ctx context.Context) error {
 }
 
 func (s *SSE) handleSSE(w http.ResponseWriter, r *http.Request) {
-	flusher, ok := w.(http.Flusher)
-	if !ok {
+	if _, ok := w.(http.Flusher); !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
 		return
 	}
 
-	s.mu.Lock()
-	// Reject if a session is already active.
-	select {
-	case <-s.sseReady:
-		s.mu.Unlock()
-		http.Error(w, "session already established", http.StatusConflict)
+	id, err := newSessionID()
+	if err != nil {
+		http.Error(w, "generating session id", http.StatusInternalServerError)
 		return
-	default:
 	}
 
-	b := make([]byte, 16)
-	if _, err := rand.Read(b); err != nil {
+	session := newSession(id, newEventWriter(w, s.opts.writeTimeout()))
+
+	s.mu.Lock()
+	select {
+	case <-s.done:
 		s.mu.Unlock()
-		http.Error(w, "generating session id", http.StatusInternalServerError)
+		http.Error(w, "server shutting down", http.StatusServiceUnavailable)
 		return
+	default:
 	}
-
-	s.sessionID = hex.EncodeToString(b)
-	s.sseWriter = w
-	s.sseFlusher = flusher
-	close(s.sseReady)
+	s.sessions[session.id] = session
+	s.wg.Add(1)
 	s.mu.Unlock()
 
+	defer s.wg.Done()
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
 	// Send the endpoint event so the client knows where to POST.
-	fmt.Fprintf(w, "event: endpoint\ndata: /message?sessionId=%s\n\n", s.sessionID)
-	flusher.Flush()
+	if err := session.send("event: endpoint\ndata: /message?sessionId=%s\n\n", session.id); err != nil {
+		s.mu.Lock()
+		delete(s.sessions, session.id)
+		s.mu.Unlock()
+		return
+	}
 
-	// Block until shutdown or client disconnect.
-	select {
-	case <-s.done:
-	case <-r.Context().Done():
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	handlerDone := make(chan struct{})
+	go func() {
+		defer close(handlerDone)
+		defer session.Close()
+		if s.handler != nil {
+			s.handler(ctx, session)
+		}
+	}()
+
+	keepAlive := time.NewTicker(s.opts.keepAlive())
+	defer keepAlive.Stop()
+
+	// Block until shutdown, client disconnect, or the session is closed.
+	// Keepalive comments stop proxies from dropping an idle stream, and a
+	// failed write closes the session of a client that stopped reading.
+wait:
+	for {
+		select {
+		case <-s.done:
+			break wait
+		case <-r.Context().Done():
+			break wait
+		case <-session.done:
+			break wait
+		case <-keepAlive.C:
+			session.send(": keepalive\n\n")
+		}
 	}
 
-	// Client disconnected — tear down the session.
+	// Tear down only this session; other sessions keep running.
 	s.mu.Lock()
-	s.sseWriter = nil
-	s.sseFlusher = nil
+	delete(s.sessions, session.id)
 	s.mu.Unlock()
 
-	s.closeDone()
+	session.detach()
+	session.Close()
+	cancel()
+
+	<-handlerDone
 }
 
 func (s *SSE) handleMessage(w http.ResponseWriter, r *http.Request) {
-	// Ensure SSE stream is established first.
-	select {
-	case <-s.sseReady:
-	default:
+	s.mu.Lock()
+	established := len(s.sessions) > 0
+	session := s.sessions[r.URL.Query().Get("sessionId")]
+	s.mu.Unlock()
+
+	// Ensure an SSE stream is established first.
+	if !established {
 		http.Error(w, "SSE stream not established", http.StatusServiceUnavailable)
 		return
 	}
 
-	qid := r.URL.Query().Get("sessionId")
-	if qid == "" || qid != s.sessionID {
+	if session == nil {
 		http.Error(w, "invalid or missing session ID", http.StatusForbidden)
 		return
 	}
@@ -148,15 +184,65 @@ func (s *SSE) handleMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	select {
-	case s.incoming <- &msg:
+	case session.incoming <- &msg:
 		w.WriteHeader(http.StatusAccepted)
+	case <-session.done:
+		http.Error(w, "session closed", http.StatusServiceUnavailable)
 	case <-s.done:
 		http.Error(w, "server shutting down", http.StatusServiceUnavailable)
 	}
 }
 
-// Read returns the next client message, or io.EOF when the connection closes.
-func (s *SSE) Read() (*jsonrpc.Message, error) {
+// SessionCount returns the number of connected sessions.
+func (s *SSE) SessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+// Close shuts down the HTTP server, ends every session, and waits for
+// their handlers to return.
+func (s *SSE) Close() error {
+	s.mu.Lock()
+	s.closeOnce.Do(func() { close(s.done) })
+	s.mu.Unlock()
+
+	err := s.server.Shutdown(context.Background())
+	s.wg.Wait()
+
+	return err
+}
+
+// Session is one client connection of the SSE transport, with its own
+// outgoing event stream and incoming message queue. It implements the
+// go-mcp transport.Transport interface.
+type Session struct {
+	id       string
+	events   *eventWriter
+	incoming chan *jsonrpc.Message
+
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+
+func newSession(id string, events *eventWriter) *Session {
+	return &Session{
+		id:       id,
+		events:   events,
+		incoming: make(chan *jsonrpc.Message, 16),
+		done:     make(chan struct{}),
+	}
+}
+
+// ID returns the session ID clients pass as the sessionId query parameter.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Read returns the next client message, or io.EOF when the session closes.
+func (s *Session) Read() (*jsonrpc.Message, error) {
 	select {
 	case msg := <-s.incoming:
 		return msg, nil
@@ -166,33 +252,80 @@ func (s *SSE) Read() (*jsonrpc.Message, error) {
 }
 
 // Write sends a JSON-RPC message to the client as an SSE event.
-func (s *SSE) Write(msg *jsonrpc.Message) error {
+func (s *Session) Write(msg *jsonrpc.Message) error {
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("marshaling message: %w", err)
 	}
 
+	return s.send("event: message\ndata: %s\n\n", data)
+}
+
+// send writes raw SSE data to the stream. If the write fails or times out
+// the client is considered gone and the session is closed.
+func (s *Session) send(format string, args ...any) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.sseWriter == nil {
+	if s.events == nil {
 		return fmt.Errorf("SSE stream not connected")
 	}
 
-	if _, err := fmt.Fprintf(s.sseWriter, "event: message\ndata: %s\n\n", data); err != nil {
+	if err := s.events.write(format, args...); err != nil {
+		s.Close()
 		return fmt.Errorf("writing SSE event: %w", err)
 	}
 
-	s.sseFlusher.Flush()
 	return nil
 }
 
-// Close shuts down the HTTP server and signals all goroutines to stop.
-func (s *SSE) Close() error {
-	s.closeDone()
-	return s.server.Shutdown(context.Background())
+// Close ends the session. The SSE stream is closed and Read returns io.EOF.
+func (s *Session) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return nil
 }
 
-func (s *SSE) closeDone() {
-	s.closeOnce.Do(func() { close(s.done) })
+// detach drops the response writer once the HTTP handler returns, after
+// which it must not be used.
+func (s *Session) detach() {
+	s.mu.Lock()
+	s.events = nil
+	s.mu.Unlock()
+}
+
+// eventWriter writes SSE data to a response, flushing it and bounding each
+// write with a deadline so a client that stops reading cannot block the
+// writer forever.
+type eventWriter struct {
+	w       io.Writer
+	rc      *http.ResponseController
+	timeout time.Duration
+}
+
+func newEventWriter(w http.ResponseWriter, timeout time.Duration) *eventWriter {
+	return &eventWriter{
+		w:       w,
+		rc:      http.NewResponseController(w),
+		timeout: timeout,
+	}
+}
+
+func (e *eventWriter) write(format string, args ...any) error {
+	// Response writers that cannot set deadlines still get unbounded writes.
+	if err := e.rc.SetWriteDeadline(time.Now().Add(e.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(e.w, format, args...); err != nil {
+		return err
+	}
+
+	if err := e.rc.Flush(); err != nil {
+		return err
+	}
+
+	// Clear the deadline so it cannot fail the server's own final writes
+	// once the stream ends.
+	e.rc.SetWriteDeadline(time.Time{})
+	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -17,15 +18,62 @@ import (
 	intTransport "github.com/friedenberg/grit/internal/transport"
 )
 
-// startSSE creates and starts an SSE transport on an ephemeral port.
-func startSSE(t *testing.T) *intTransport.SSE {
+// startSSE creates and starts an SSE transport on an ephemeral port. Each
+// new session is sent on the returned channel and kept open until the
+// client disconnects or the transport closes.
+func startSSE(t *testing.T) (*intTransport.SSE, <-chan intTransport.SessionTransport) {
 	t.Helper()
-	s := intTransport.NewSSE(":0")
+	return startSSEWithOptions(t, intTransport.HTTPOptions{})
+}
+
+// startSSEWithOptions is startSSE with transport options.
+func startSSEWithOptions(t *testing.T, opts intTransport.HTTPOptions) (*intTransport.SSE, <-chan intTransport.SessionTransport) {
+	t.Helper()
+	sessions := make(chan intTransport.SessionTransport, 8)
+	s := intTransport.NewSSE(":0", func(ctx context.Context, session intTransport.SessionTransport) {
+		sessions <- session
+		<-ctx.Done()
+	}, opts)
 	if err := s.Start(context.Background()); err != nil {
 		t.Fatalf("starting SSE: %v", err)
 	}
 	t.Cleanup(func() { s.Close() })
-	return s
+	return s, sessions
+}
+
+// nextSession waits for the handler to receive a new session.
+func nextSession(t *testing.T, sessions <-chan intTransport.SessionTransport) intTransport.SessionTransport {
+	t.Helper()
+	select {
+	case session := <-sessions:
+		return session
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for session")
+		return nil
+	}
+}
+
+// readEvent reads SSE lines until a complete event of the given type.
+func readEvent(t *testing.T, body io.Reader, want string) string {
+	t.Helper()
+	scanner := bufio.NewScanner(body)
+	var eventType, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "event: ") {
+			eventType = strings.TrimPrefix(line, "event: ")
+		} else if strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+		} else if line == "" && eventType == want {
+			break
+		}
+	}
+
+	if eventType != want {
+		t.Fatalf("expected %s event, got %q", want, eventType)
+	}
+
+	return data
 }
 
 func baseURL(s *intTransport.SSE) string {
@@ -80,7 +128,7 @@ func postMessage(t *testing.T, s *intTransport.SSE, endpoint string, msg *jsonrp
 }
 
 func TestRoundTrip(t *testing.T) {
-	s := startSSE(t)
+	s, sessions := startSSE(t)
 
 	var endpoint string
 	var sseResp *http.Response
@@ -100,6 +148,11 @@ func TestRoundTrip(t *testing.T) {
 	}
 	defer sseResp.Body.Close()
 
+	session := nextSession(t, sessions)
+	if !strings.HasSuffix(endpoint, "sessionId="+session.ID()) {
+		t.Fatalf("endpoint %q does not name session %s", endpoint, session.ID())
+	}
+
 	// POST a request
 	reqMsg, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(1), "test/method", map[string]string{"key": "value"})
 	resp := postMessage(t, s, endpoint, reqMsg)
@@ -114,7 +167,7 @@ func TestRoundTrip(t *testing.T) {
 	var readErr error
 	go func() {
 		defer close(readDone)
-		readMsg, readErr = s.Read()
+		readMsg, readErr = session.Read()
 	}()
 
 	select {
@@ -132,27 +185,11 @@ func TestRoundTrip(t *testing.T) {
 
 	// Write a response and verify it appears on the SSE stream
 	respMsg, _ := jsonrpc.NewResponse(jsonrpc.NewNumberID(1), map[string]string{"result": "ok"})
-	if err := s.Write(respMsg); err != nil {
+	if err := session.Write(respMsg); err != nil {
 		t.Fatalf("Write: %v", err)
 	}
 
-	// Read the SSE event from the stream
-	scanner := bufio.NewScanner(sseResp.Body)
-	var eventType, data string
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.HasPrefix(line, "event: ") {
-			eventType = strings.TrimPrefix(line, "event: ")
-		} else if strings.HasPrefix(line, "data: ") {
-			data = strings.TrimPrefix(line, "data: ")
-		} else if line == "" && eventType == "message" {
-			break
-		}
-	}
-
-	if eventType != "message" {
-		t.Fatalf("expected message event, got %q", eventType)
-	}
+	data := readEvent(t, sseResp.Body, "message")
 
 	var gotMsg jsonrpc.Message
 	if err := json.Unmarshal([]byte(data), &gotMsg); err != nil {
@@ -169,7 +206,7 @@ func TestRoundTrip(t *testing.T) {
 }
 
 func TestPostBeforeSSE(t *testing.T) {
-	s := startSSE(t)
+	s, _ := startSSE(t)
 
 	msg, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(1), "test", nil)
 	body, _ := json.Marshal(msg)
@@ -189,7 +226,7 @@ func TestPostBeforeSSE(t *testing.T) {
 }
 
 func TestWrongSessionID(t *testing.T) {
-	s := startSSE(t)
+	s, _ := startSSE(t)
 
 	done := make(chan struct{})
 	var sseResp *http.Response
@@ -223,7 +260,7 @@ func TestWrongSessionID(t *testing.T) {
 }
 
 func TestClientDisconnectCausesEOF(t *testing.T) {
-	s := startSSE(t)
+	s, sessions := startSSE(t)
 
 	done := make(chan struct{})
 	var sseResp *http.Response
@@ -238,17 +275,16 @@ func TestClientDisconnectCausesEOF(t *testing.T) {
 		t.Fatal("timeout connecting SSE")
 	}
 
+	session := nextSession(t, sessions)
+
 	// Close the SSE response body to simulate client disconnect.
 	sseResp.Body.Close()
 
-	// Give the server a moment to detect the disconnect.
-	time.Sleep(100 * time.Millisecond)
-
 	readDone := make(chan struct{})
 	var readErr error
 	go func() {
 		defer close(readDone)
-		_, readErr = s.Read()
+		_, readErr = session.Read()
 	}()
 
 	select {
@@ -263,36 +299,124 @@ func TestClientDisconnectCausesEOF(t *testing.T) {
 }
 
 func TestMultipleSSEConnections(t *testing.T) {
-	s := startSSE(t)
+	s, sessions := startSSE(t)
 
-	done := make(chan struct{})
-	var sseResp *http.Response
-	go func() {
-		defer close(done)
-		_, sseResp = connectSSE(t, s)
-	}()
+	endpointA, respA := connectSSE(t, s)
+	defer respA.Body.Close()
+	sessionA := nextSession(t, sessions)
 
-	select {
-	case <-done:
-	case <-time.After(2 * time.Second):
-		t.Fatal("timeout connecting SSE")
+	endpointB, respB := connectSSE(t, s)
+	defer respB.Body.Close()
+	sessionB := nextSession(t, sessions)
+
+	if sessionA.ID() == sessionB.ID() || endpointA == endpointB {
+		t.Fatalf("sessions share an ID: %s", sessionA.ID())
 	}
-	defer sseResp.Body.Close()
 
-	// Second connection should get 409.
-	resp, err := http.Get(baseURL(s) + "/sse")
+	if n := s.SessionCount(); n != 2 {
+		t.Fatalf("expected 2 sessions, got %d", n)
+	}
+
+	// Messages are routed by sessionId.
+	msg, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(1), "for/b", nil)
+	resp := postMessage(t, s, endpointB, msg)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+
+	got, err := sessionB.Read()
 	if err != nil {
-		t.Fatalf("GET /sse: %v", err)
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Method != "for/b" {
+		t.Fatalf("expected method for/b, got %s", got.Method)
+	}
+
+	// Responses go to the session's own stream.
+	reply, _ := jsonrpc.NewResponse(jsonrpc.NewNumberID(1), map[string]string{"to": "a"})
+	if err := sessionA.Write(reply); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	data := readEvent(t, respA.Body, "message")
+	if !strings.Contains(data, `"to":"a"`) {
+		t.Fatalf("unexpected event data on session A: %s", data)
 	}
+}
+
+func TestDisconnectKeepsOtherSessions(t *testing.T) {
+	s, sessions := startSSE(t)
+
+	_, respA := connectSSE(t, s)
+	sessionA := nextSession(t, sessions)
+
+	endpointB, respB := connectSSE(t, s)
+	defer respB.Body.Close()
+	sessionB := nextSession(t, sessions)
+
+	respA.Body.Close()
+
+	if _, err := sessionA.Read(); err != io.EOF {
+		t.Fatalf("expected io.EOF on disconnected session, got %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.SessionCount() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 1 session after disconnect, got %d", s.SessionCount())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	msg, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(2), "still/alive", nil)
+	resp := postMessage(t, s, endpointB, msg)
 	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
 
-	if resp.StatusCode != http.StatusConflict {
-		t.Fatalf("expected 409, got %d", resp.StatusCode)
+	got, err := sessionB.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Method != "still/alive" {
+		t.Fatalf("expected method still/alive, got %s", got.Method)
+	}
+}
+
+func TestCloseEndsAllSessions(t *testing.T) {
+	s, sessions := startSSE(t)
+
+	_, respA := connectSSE(t, s)
+	defer respA.Body.Close()
+	sessionA := nextSession(t, sessions)
+
+	_, respB := connectSSE(t, s)
+	defer respB.Body.Close()
+	sessionB := nextSession(t, sessions)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		s.Close()
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout closing SSE")
+	}
+
+	for _, session := range []intTransport.SessionTransport{sessionA, sessionB} {
+		if _, err := session.Read(); err != io.EOF {
+			t.Fatalf("expected io.EOF after Close, got %v", err)
+		}
 	}
 }
 
 func TestConcurrentWrites(t *testing.T) {
-	s := startSSE(t)
+	s, sessions := startSSE(t)
 
 	done := make(chan struct{})
 	var sseResp *http.Response
@@ -308,6 +432,8 @@ func TestConcurrentWrites(t *testing.T) {
 	}
 	defer sseResp.Body.Close()
 
+	session := nextSession(t, sessions)
+
 	// Fire off concurrent writes — should not panic or produce errors.
 	var wg sync.WaitGroup
 	errs := make(chan error, 10)
@@ -317,7 +443,7 @@ func TestConcurrentWrites(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			msg, _ := jsonrpc.NewResponse(jsonrpc.NewNumberID(int64(i)), map[string]int{"n": i})
-			if err := s.Write(msg); err != nil {
+			if err := session.Write(msg); err != nil {
 				errs <- err
 			}
 		}()
@@ -332,7 +458,7 @@ func TestConcurrentWrites(t *testing.T) {
 }
 
 func TestMalformedJSON(t *testing.T) {
-	s := startSSE(t)
+	s, _ := startSSE(t)
 
 	done := make(chan struct{})
 	var endpoint string
@@ -369,3 +495,81 @@ func TestMalformedJSON(t *testing.T) {
 		t.Fatalf("expected 400, got %d", resp.StatusCode)
 	}
 }
+
+func TestKeepAlive(t *testing.T) {
+	s, _ := startSSEWithOptions(t, intTransport.HTTPOptions{KeepAlive: 20 * time.Millisecond})
+
+	_, resp := connectSSE(t, s)
+	defer resp.Body.Close()
+
+	found := make(chan bool, 1)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if scanner.Text() == ": keepalive" {
+				found <- true
+				return
+			}
+		}
+		found <- false
+	}()
+
+	select {
+	case ok := <-found:
+		if !ok {
+			t.Fatal("stream ended before a keepalive")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for keepalive comment")
+	}
+}
+
+func TestStalledClientClosesOnlyItsSession(t *testing.T) {
+	s, sessions := startSSEWithOptions(t, intTransport.HTTPOptions{WriteTimeout: 50 * time.Millisecond})
+
+	// Client A connects but never reads past the endpoint event.
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /sse HTTP/1.1\r\nHost: grit\r\n\r\n")
+	stalled := nextSession(t, sessions)
+
+	endpointB, respB := connectSSE(t, s)
+	defer respB.Body.Close()
+	healthy := nextSession(t, sessions)
+
+	// Fill the socket buffers until a write times out.
+	big, _ := jsonrpc.NewNotification("test/big", strings.Repeat("x", 1<<20))
+	deadline := time.Now().Add(5 * time.Second)
+	for stalled.Write(big) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("writes to a stalled client never failed")
+		}
+	}
+
+	if _, err := stalled.Read(); err != io.EOF {
+		t.Fatalf("expected io.EOF on stalled session, got %v", err)
+	}
+
+	waitUntil := time.Now().Add(2 * time.Second)
+	for s.SessionCount() != 1 {
+		if time.Now().After(waitUntil) {
+			t.Fatalf("expected 1 session, got %d", s.SessionCount())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	msg, _ := jsonrpc.NewRequest(jsonrpc.NewNumberID(1), "still/alive", nil)
+	resp := postMessage(t, s, endpointB, msg)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+
+	got, err := healthy.Read()
+	if err != nil || got.Method != "still/alive" {
+		t.Fatalf("healthy session: got %v, %v", got, err)
+	}
+}
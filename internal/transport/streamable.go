@@ -0,0 +1,820 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/jsonrpc"
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/protocol"
+)
+
+const (
+	// SessionIDHeader carries the session ID of a Streamable HTTP session.
+	SessionIDHeader = "Mcp-Session-Id"
+
+	// maxStreamEvents bounds the events kept per stream for resumption.
+	maxStreamEvents = 1024
+
+	// maxCompletedStreams is how many finished streams a session keeps so
+	// that clients can still resume them with Last-Event-ID.
+	maxCompletedStreams = 32
+
+	// maxRequestBody bounds the size of a POSTed JSON-RPC message or batch.
+	maxRequestBody = 4 << 20
+
+	// standaloneStreamID is the stream of server messages that are not
+	// responses, delivered on the GET stream.
+	standaloneStreamID = "0"
+)
+
+// StreamableHTTP implements the MCP Streamable HTTP transport on a single
+// /mcp endpoint. Clients POST JSON-RPC messages and get the responses back
+// either as a JSON body or as an SSE stream, GET opens a stream for server
+// messages that are not responses, and DELETE ends the session. Sessions
+// are identified by the Mcp-Session-Id header, assigned on initialize, and
+// end after sitting idle for HTTPOptions.SessionIdleTimeout.
+type StreamableHTTP struct {
+	server   *http.Server
+	listener net.Listener
+	handler  SessionHandler
+	opts     HTTPOptions
+	ctx      context.Context
+
+	sessions  map[string]*StreamSession
+	wg        sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+
+// NewStreamableHTTP creates a new Streamable HTTP transport that listens on
+// the given address and calls handler for every session. Requests are
+// checked against opts. The address is a TCP host:port or "unix:" followed
+// by a socket path.
+func NewStreamableHTTP(addr string, handler SessionHandler, opts HTTPOptions) *StreamableHTTP {
+	return &StreamableHTTP{
+		handler:  handler,
+		opts:     opts,
+		sessions: make(map[string]*StreamSession),
+		done:     make(chan struct{}),
+		server: &http.Server{
+			Addr: addr,
+		},
+	}
+}
+
+// Addr returns the listener's address. Only valid after Start returns.
+func (s *StreamableHTTP) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Start begins serving HTTP on the configured address. Session handlers
+// run with contexts derived from ctx.
+func (s *StreamableHTTP) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /mcp", s.handlePost)
+	mux.HandleFunc("GET /mcp", s.handleGet)
+	mux.HandleFunc("DELETE /mcp", s.handleDelete)
+	s.server.Handler = s.opts.routes(mux, s.SessionCount)
+	s.server.BaseContext = func(_ net.Listener) context.Context { return ctx }
+	s.ctx = ctx
+
+	ln, err := listen(s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", s.server.Addr, err)
+	}
+
+	s.listener = ln
+
+	s.wg.Add(1)
+	go s.reapIdleSessions()
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Streamable HTTP server error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *StreamableHTTP) handlePost(w http.ResponseWriter, r *http.Request) {
+	wantJSON := acceptsMediaType(r, "application/json")
+	wantSSE := acceptsMediaType(r, "text/event-stream")
+	if !wantJSON && !wantSSE {
+		http.Error(w, "Accept must include application/json or text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "reading body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	msgs, batch, err := decodeMessages(body)
+	if err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var session *StreamSession
+	if r.Header.Get(SessionIDHeader) == "" && containsInitialize(msgs) {
+		session, err = s.newSession()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	} else if session = s.lookupSession(w, r); session == nil {
+		return
+	}
+	defer session.begin()()
+
+	w.Header().Set(SessionIDHeader, session.id)
+
+	var requests []*jsonrpc.Message
+	for _, msg := range msgs {
+		if msg.IsRequest() {
+			requests = append(requests, msg)
+		}
+	}
+
+	// The stream is registered before the requests are queued so responses
+	// cannot arrive ahead of it.
+	var stream *eventStream
+	if len(requests) > 0 {
+		if stream, err = session.openStream(requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, msg := range msgs {
+		select {
+		case session.incoming <- msg:
+		case <-session.done:
+			http.Error(w, "session closed", http.StatusNotFound)
+			return
+		case <-s.done:
+			http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	if stream == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	// Clients that accept both get a plain JSON reply; SSE is only used
+	// when the client asks for it alone.
+	if wantJSON {
+		s.replyJSON(w, r, session, stream, batch)
+		return
+	}
+
+	s.serveStream(w, r, session, stream, 0)
+}
+
+// replyJSON waits for every response of stream and writes them as the body,
+// as an array if the request was a batch.
+func (s *StreamableHTTP) replyJSON(w http.ResponseWriter, r *http.Request, session *StreamSession, stream *eventStream, batch bool) {
+	var responses []json.RawMessage
+	seq := 0
+
+	for {
+		events, complete, changed := session.eventsAfter(stream, seq)
+		for _, event := range events {
+			responses = append(responses, event.data)
+			seq = event.seq
+		}
+
+		if complete {
+			break
+		}
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-session.done:
+			http.Error(w, "session closed", http.StatusNotFound)
+			return
+		}
+	}
+
+	session.dropStream(stream)
+
+	var body []byte
+	if batch {
+		body, _ = json.Marshal(responses)
+	} else {
+		body = responses[0]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (s *StreamableHTTP) handleGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsMediaType(r, "text/event-stream") {
+		http.Error(w, "Accept must include text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+
+	session := s.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+	defer session.begin()()
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		stream, seq, ok := session.attachStandalone()
+		if !ok {
+			http.Error(w, "a stream is already open for this session", http.StatusConflict)
+			return
+		}
+		defer session.detachStandalone()
+
+		s.serveStream(w, r, session, stream, seq)
+		return
+	}
+
+	streamID, seq, ok := parseEventID(lastEventID)
+	if !ok {
+		http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+		return
+	}
+
+	if streamID == standaloneStreamID {
+		stream, _, ok := session.attachStandalone()
+		if !ok {
+			http.Error(w, "a stream is already open for this session", http.StatusConflict)
+			return
+		}
+		defer session.detachStandalone()
+
+		s.serveStream(w, r, session, stream, seq)
+		return
+	}
+
+	stream := session.stream(streamID)
+	if stream == nil {
+		http.Error(w, "event stream is no longer available", http.StatusNotFound)
+		return
+	}
+
+	s.serveStream(w, r, session, stream, seq)
+}
+
+func (s *StreamableHTTP) handleDelete(w http.ResponseWriter, r *http.Request) {
+	session := s.lookupSession(w, r)
+	if session == nil {
+		return
+	}
+
+	session.Close()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveStream writes the events of stream after seq as SSE events until the
+// stream completes, the client disconnects, or the session ends. Events
+// carry "<stream>-<seq>" IDs so the client can resume with Last-Event-ID.
+// Idle streams get keepalive comments, and a write that fails or times out
+// ends the stream; its events stay buffered for the client to resume.
+func (s *StreamableHTTP) serveStream(w http.ResponseWriter, r *http.Request, session *StreamSession, stream *eventStream, seq int) {
+	if _, ok := w.(http.Flusher); !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	events := newEventWriter(w, s.opts.writeTimeout())
+	if err := events.write(""); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(s.opts.keepAlive())
+	defer keepAlive.Stop()
+
+	for {
+		pending, complete, changed := session.eventsAfter(stream, seq)
+		for _, event := range pending {
+			if err := events.write("id: %s-%d\nevent: message\ndata: %s\n\n", stream.id, event.seq, event.data); err != nil {
+				return
+			}
+			seq = event.seq
+		}
+
+		if complete {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			if err := events.write(": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-session.done:
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *StreamableHTTP) newSession() (*StreamSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("generating session id: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	session := newStreamSession(id, cancel)
+
+	s.mu.Lock()
+	select {
+	case <-s.done:
+		s.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("server shutting down")
+	default:
+	}
+	if len(s.sessions) >= s.opts.maxSessions() {
+		s.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("too many sessions")
+	}
+	s.sessions[id] = session
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.sessions, id)
+			s.mu.Unlock()
+		}()
+		defer session.Close()
+
+		if s.handler != nil {
+			s.handler(ctx, session)
+		}
+	}()
+
+	return session, nil
+}
+
+// reapIdleSessions ends sessions that have been idle for longer than the
+// idle timeout, the way DELETE would, until the transport closes.
+func (s *StreamableHTTP) reapIdleSessions() {
+	defer s.wg.Done()
+
+	timeout := s.opts.sessionIdleTimeout()
+	ticker := time.NewTicker(max(timeout/4, 10*time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+
+		var idle []*StreamSession
+		s.mu.Lock()
+		for _, session := range s.sessions {
+			if session.idleFor() >= timeout {
+				idle = append(idle, session)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, session := range idle {
+			session.Close()
+		}
+	}
+}
+
+// lookupSession returns the session named by the request's Mcp-Session-Id
+// header, or writes an error response and returns nil.
+func (s *StreamableHTTP) lookupSession(w http.ResponseWriter, r *http.Request) *StreamSession {
+	id := r.Header.Get(SessionIDHeader)
+	if id == "" {
+		http.Error(w, "missing "+SessionIDHeader+" header", http.StatusBadRequest)
+		return nil
+	}
+
+	s.mu.Lock()
+	session := s.sessions[id]
+	s.mu.Unlock()
+
+	if session == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return nil
+	}
+
+	return session
+}
+
+// SessionCount returns the number of active sessions.
+func (s *StreamableHTTP) SessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+// Close shuts down the HTTP server, ends every session, and waits for
+// their handlers to return.
+func (s *StreamableHTTP) Close() error {
+	s.mu.Lock()
+	s.closeOnce.Do(func() { close(s.done) })
+	sessions := make([]*StreamSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		session.Close()
+	}
+
+	err := s.server.Shutdown(context.Background())
+	s.wg.Wait()
+
+	return err
+}
+
+// StreamSession is one client session of the Streamable HTTP transport. It
+// implements the go-mcp transport.Transport interface: Read returns the
+// messages POSTed by the client, and Write routes each response to the
+// stream of the POST that carried its request. Other server messages go to
+// the standalone stream the client opens with GET.
+type StreamSession struct {
+	id       string
+	incoming chan *jsonrpc.Message
+	cancel   context.CancelFunc
+
+	nextStream int
+	streams    map[string]*eventStream
+	completed  []string
+	pending    map[string]*eventStream
+	standalone *eventStream
+	attached   bool
+
+	// active counts the requests being served for the session, and
+	// lastActive is when one last started or finished.
+	active     int
+	lastActive time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.Mutex
+}
+
+func newStreamSession(id string, cancel context.CancelFunc) *StreamSession {
+	return &StreamSession{
+		id:         id,
+		incoming:   make(chan *jsonrpc.Message, 16),
+		cancel:     cancel,
+		nextStream: 1,
+		streams:    make(map[string]*eventStream),
+		pending:    make(map[string]*eventStream),
+		standalone: newEventStream(standaloneStreamID, 0),
+		lastActive: time.Now(),
+		done:       make(chan struct{}),
+	}
+}
+
+// begin marks a request for the session as in flight and returns the
+// function that marks it finished.
+func (s *StreamSession) begin() func() {
+	s.mu.Lock()
+	s.active++
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		s.active--
+		s.lastActive = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// idleFor returns how long the session has had no request in flight, or
+// zero while one is.
+func (s *StreamSession) idleFor() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active > 0 {
+		return 0
+	}
+	return time.Since(s.lastActive)
+}
+
+// ID returns the session ID clients send in the Mcp-Session-Id header.
+func (s *StreamSession) ID() string {
+	return s.id
+}
+
+// Read returns the next client message, or io.EOF when the session closes.
+func (s *StreamSession) Read() (*jsonrpc.Message, error) {
+	select {
+	case msg := <-s.incoming:
+		return msg, nil
+	case <-s.done:
+		return nil, io.EOF
+	}
+}
+
+// Write queues a JSON-RPC message for delivery to the client.
+func (s *StreamSession) Write(msg *jsonrpc.Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return fmt.Errorf("session closed")
+	default:
+	}
+
+	if msg.IsResponse() {
+		key := idKey(msg.ID)
+		if stream := s.pending[key]; stream != nil {
+			delete(s.pending, key)
+			stream.append(data)
+			stream.pending--
+			if stream.pending == 0 {
+				s.completeStream(stream)
+			}
+			stream.notify()
+			return nil
+		}
+	}
+
+	s.standalone.append(data)
+	s.standalone.notify()
+	return nil
+}
+
+// Close ends the session: open streams end, Read returns io.EOF, and the
+// handler's context is canceled.
+func (s *StreamSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.cancel()
+	})
+	return nil
+}
+
+// openStream creates the response stream for a POST carrying requests.
+// Responses are routed by request ID, so it refuses IDs repeated within
+// the requests or still awaiting a response from an earlier POST; their
+// responses could not all reach the stream and it would never complete.
+func (s *StreamSession) openStream(requests []*jsonrpc.Message) (*eventStream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(requests))
+	for _, req := range requests {
+		key := idKey(req.ID)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate request id %s", key)
+		}
+		if s.pending[key] != nil {
+			return nil, fmt.Errorf("request id %s is already in flight", key)
+		}
+		seen[key] = true
+	}
+
+	stream := newEventStream(strconv.Itoa(s.nextStream), len(requests))
+	s.nextStream++
+	s.streams[stream.id] = stream
+
+	for _, req := range requests {
+		s.pending[idKey(req.ID)] = stream
+	}
+
+	return stream, nil
+}
+
+// completeStream keeps a finished stream for resumption, dropping the
+// oldest finished streams beyond maxCompletedStreams.
+func (s *StreamSession) completeStream(stream *eventStream) {
+	stream.complete = true
+	s.completed = append(s.completed, stream.id)
+
+	for len(s.completed) > maxCompletedStreams {
+		delete(s.streams, s.completed[0])
+		s.completed = s.completed[1:]
+	}
+}
+
+// dropStream forgets a stream whose responses were delivered as JSON and
+// so cannot be resumed.
+func (s *StreamSession) dropStream(stream *eventStream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.streams, stream.id)
+	for i, id := range s.completed {
+		if id == stream.id {
+			s.completed = append(s.completed[:i], s.completed[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *StreamSession) stream(id string) *eventStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.streams[id]
+}
+
+// attachStandalone claims the standalone stream for a GET request and
+// returns the sequence number of its latest event. Only one GET stream may
+// be attached at a time.
+func (s *StreamSession) attachStandalone() (*eventStream, int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attached {
+		return nil, 0, false
+	}
+
+	s.attached = true
+	return s.standalone, s.standalone.last, true
+}
+
+func (s *StreamSession) detachStandalone() {
+	s.mu.Lock()
+	s.attached = false
+	s.mu.Unlock()
+}
+
+// eventsAfter returns the buffered events of stream after seq, whether the
+// stream is complete, and a channel closed on its next change.
+func (s *StreamSession) eventsAfter(stream *eventStream, seq int) ([]streamEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []streamEvent
+	for _, event := range stream.events {
+		if event.seq > seq {
+			events = append(events, event)
+		}
+	}
+
+	return events, stream.complete, stream.changed
+}
+
+// eventStream is the ordered, bounded log of events sent on one SSE stream.
+// Its fields are guarded by the owning session's mutex.
+type eventStream struct {
+	id       string
+	events   []streamEvent
+	last     int
+	pending  int
+	complete bool
+	changed  chan struct{}
+}
+
+type streamEvent struct {
+	seq  int
+	data []byte
+}
+
+func newEventStream(id string, pending int) *eventStream {
+	return &eventStream{
+		id:      id,
+		pending: pending,
+		changed: make(chan struct{}),
+	}
+}
+
+func (e *eventStream) append(data []byte) {
+	e.last++
+	e.events = append(e.events, streamEvent{seq: e.last, data: data})
+	if len(e.events) > maxStreamEvents {
+		e.events = e.events[len(e.events)-maxStreamEvents:]
+	}
+}
+
+// notify wakes everyone waiting on the stream.
+func (e *eventStream) notify() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// decodeMessages decodes a single JSON-RPC message or a batch.
+func decodeMessages(body []byte) ([]*jsonrpc.Message, bool, error) {
+	trimmed := strings.TrimSpace(string(body))
+
+	if strings.HasPrefix(trimmed, "[") {
+		var msgs []*jsonrpc.Message
+		if err := json.Unmarshal(body, &msgs); err != nil {
+			return nil, false, err
+		}
+
+		if len(msgs) == 0 {
+			return nil, false, fmt.Errorf("empty batch")
+		}
+
+		for _, msg := range msgs {
+			if msg == nil {
+				return nil, false, fmt.Errorf("null message in batch")
+			}
+		}
+
+		return msgs, true, nil
+	}
+
+	var msg jsonrpc.Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, false, err
+	}
+
+	return []*jsonrpc.Message{&msg}, false, nil
+}
+
+func containsInitialize(msgs []*jsonrpc.Message) bool {
+	for _, msg := range msgs {
+		if msg.IsRequest() && msg.Method == protocol.MethodInitialize {
+			return true
+		}
+	}
+	return false
+}
+
+// idKey returns a map key for a request ID that keeps numeric and string
+// IDs distinct.
+func idKey(id *jsonrpc.ID) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
+
+// parseEventID splits a "<stream>-<seq>" event ID.
+func parseEventID(id string) (string, int, bool) {
+	i := strings.LastIndex(id, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+
+	seq, err := strconv.Atoi(id[i+1:])
+	if err != nil || seq < 0 {
+		return "", 0, false
+	}
+
+	return id[:i], seq, true
+}
+
+// acceptsMediaType reports whether the request's Accept header allows
+// mediaType. A missing header or */* accepts anything.
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return true
+	}
+
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			parsed, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			if parsed == mediaType || parsed == "*/*" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
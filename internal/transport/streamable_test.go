@@ -0,0 +1,476 @@
+package transport_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amarbel-llc/purse-first/libs/go-mcp/jsonrpc"
+	intTransport "github.com/friedenberg/grit/internal/transport"
+)
+
+// startStreamable creates and starts a Streamable HTTP transport on an
+// ephemeral port. Every session answers each request with a result naming
+// its method; a "notify" request first sends a notification as well, and
+// a "hang" request is never answered.
+func startStreamable(t *testing.T) *intTransport.StreamableHTTP {
+	t.Helper()
+	return startStreamableWith(t, intTransport.HTTPOptions{})
+}
+
+func startStreamableWith(t *testing.T, opts intTransport.HTTPOptions) *intTransport.StreamableHTTP {
+	t.Helper()
+	s := intTransport.NewStreamableHTTP(":0", func(ctx context.Context, session intTransport.SessionTransport) {
+		for {
+			msg, err := session.Read()
+			if err != nil {
+				return
+			}
+
+			if !msg.IsRequest() || msg.Method == "hang" {
+				continue
+			}
+
+			if msg.Method == "notify" {
+				note, _ := jsonrpc.NewNotification("test/note", map[string]string{"session": session.ID()})
+				session.Write(note)
+			}
+
+			resp, _ := jsonrpc.NewResponse(*msg.ID, map[string]string{"method": msg.Method})
+			session.Write(resp)
+		}
+	}, opts)
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatalf("starting Streamable HTTP: %v", err)
+	}
+	t.Cleanup(func() {
+		// Spare connections the client dialed but never used would hold up
+		// Shutdown until they time out.
+		http.DefaultClient.CloseIdleConnections()
+		s.Close()
+	})
+	return s
+}
+
+func mcpURL(s *intTransport.StreamableHTTP) string {
+	return fmt.Sprintf("http://%s/mcp", s.Addr().String())
+}
+
+// postMCP POSTs body to /mcp with the given session ID and Accept header.
+func postMCP(t *testing.T, s *intTransport.StreamableHTTP, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, mcpURL(s), strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if sessionID != "" {
+		req.Header.Set(intTransport.SessionIDHeader, sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /mcp: %v", err)
+	}
+
+	return resp
+}
+
+// getMCP opens a GET stream on /mcp, resuming after lastEventID if set.
+func getMCP(t *testing.T, s *intTransport.StreamableHTTP, sessionID, lastEventID string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, mcpURL(s), nil)
+	if err != nil {
+		t.Fatalf("creating request: %v", err)
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(intTransport.SessionIDHeader, sessionID)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /mcp: %v", err)
+	}
+
+	return resp
+}
+
+// initialize starts a session and returns its ID.
+func initialize(t *testing.T, s *intTransport.StreamableHTTP) string {
+	t.Helper()
+	resp := postMCP(t, s, "", "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	id := resp.Header.Get(intTransport.SessionIDHeader)
+	if id == "" {
+		t.Fatal("initialize response has no session ID")
+	}
+
+	return id
+}
+
+type sseEvent struct {
+	id   string
+	data string
+}
+
+// readSSEEvents reads n events from an SSE stream.
+func readSSEEvents(t *testing.T, body io.Reader, n int) []sseEvent {
+	t.Helper()
+	scanner := bufio.NewScanner(body)
+	var events []sseEvent
+	var current sseEvent
+	for len(events) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "" && current.data != "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+
+	if len(events) != n {
+		t.Fatalf("expected %d events, got %d", n, len(events))
+	}
+
+	return events
+}
+
+func TestStreamableInitializeAssignsSession(t *testing.T) {
+	s := startStreamable(t)
+
+	resp := postMCP(t, s, "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	if resp.Header.Get(intTransport.SessionIDHeader) == "" {
+		t.Fatal("expected Mcp-Session-Id header")
+	}
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected application/json, got %q", ct)
+	}
+
+	var msg jsonrpc.Message
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	if msg.ID == nil || msg.ID.String() != "1" {
+		t.Fatalf("expected response to id 1, got %+v", msg.ID)
+	}
+
+	if n := s.SessionCount(); n != 1 {
+		t.Fatalf("expected 1 session, got %d", n)
+	}
+}
+
+func TestStreamableSessionRequired(t *testing.T) {
+	s := startStreamable(t)
+
+	resp := postMCP(t, s, "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("missing session: expected 400, got %d", resp.StatusCode)
+	}
+
+	resp = postMCP(t, s, "unknown", "application/json", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown session: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableNotificationAccepted(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	resp := postMCP(t, s, id, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableBatchJSON(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	resp := postMCP(t, s, id, "application/json",
+		`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","method":"note"},{"jsonrpc":"2.0","id":"two","method":"b"}]`)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var msgs []jsonrpc.Message
+	if err := json.NewDecoder(resp.Body).Decode(&msgs); err != nil {
+		t.Fatalf("decoding batch response: %v", err)
+	}
+
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(msgs))
+	}
+
+	got := map[string]bool{}
+	for _, msg := range msgs {
+		got[msg.ID.String()] = true
+	}
+
+	if !got["1"] || !got["two"] {
+		t.Fatalf("expected responses to 1 and two, got %v", got)
+	}
+}
+
+func TestStreamableBatchDuplicateIDs(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	resp := postMCP(t, s, id, "application/json",
+		`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":1,"method":"b"}]`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for duplicate IDs, got %d", resp.StatusCode)
+	}
+
+	// String and numeric IDs are distinct.
+	resp = postMCP(t, s, id, "application/json",
+		`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":"1","method":"b"}]`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for distinct IDs, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableIDInFlight(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	// The SSE response starts once the request's stream is registered.
+	hanging := postMCP(t, s, id, "text/event-stream", `{"jsonrpc":"2.0","id":5,"method":"hang"}`)
+	defer hanging.Body.Close()
+
+	if hanging.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", hanging.StatusCode)
+	}
+
+	resp := postMCP(t, s, id, "application/json", `{"jsonrpc":"2.0","id":5,"method":"ping"}`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an ID already in flight, got %d", resp.StatusCode)
+	}
+
+	resp = postMCP(t, s, id, "application/json", `{"jsonrpc":"2.0","id":6,"method":"ping"}`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for a fresh ID, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableSSEResponseAndResume(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	resp := postMCP(t, s, id, "text/event-stream",
+		`[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":2,"method":"b"}]`)
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	events := readSSEEvents(t, resp.Body, 2)
+	if events[0].id == "" || events[0].id == events[1].id {
+		t.Fatalf("expected distinct event IDs, got %q and %q", events[0].id, events[1].id)
+	}
+
+	// The stream ends once every request has been answered.
+	if rest, _ := io.ReadAll(resp.Body); len(bytes.TrimSpace(rest)) != 0 {
+		t.Fatalf("expected stream to end, got %q", rest)
+	}
+
+	// Resuming after the first event replays only the second.
+	resumed := getMCP(t, s, id, events[0].id)
+	defer resumed.Body.Close()
+
+	if resumed.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resumed.StatusCode)
+	}
+
+	replayed := readSSEEvents(t, resumed.Body, 1)
+	if replayed[0] != events[1] {
+		t.Fatalf("expected replay of %+v, got %+v", events[1], replayed[0])
+	}
+}
+
+func TestStreamableResumeUnknownStream(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	resp := getMCP(t, s, id, "99-1")
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableStandaloneStream(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	stream := getMCP(t, s, id, "")
+	defer stream.Body.Close()
+
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", stream.StatusCode)
+	}
+
+	second := getMCP(t, s, id, "")
+	second.Body.Close()
+	if second.StatusCode != http.StatusConflict {
+		t.Fatalf("second GET: expected 409, got %d", second.StatusCode)
+	}
+
+	// The notification goes to the GET stream, the response to the POST.
+	resp := postMCP(t, s, id, "application/json", `{"jsonrpc":"2.0","id":1,"method":"notify"}`)
+	defer resp.Body.Close()
+
+	var msg jsonrpc.Message
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if !msg.IsResponse() {
+		t.Fatalf("expected a response, got %+v", msg)
+	}
+
+	events := readSSEEvents(t, stream.Body, 1)
+	if !strings.Contains(events[0].data, "test/note") {
+		t.Fatalf("expected notification on GET stream, got %s", events[0].data)
+	}
+}
+
+func TestStreamableDeleteEndsSession(t *testing.T) {
+	s := startStreamable(t)
+	id := initialize(t, s)
+
+	req, _ := http.NewRequest(http.MethodDelete, mcpURL(s), nil)
+	req.Header.Set(intTransport.SessionIDHeader, id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE /mcp: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resp.StatusCode)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.SessionCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 0 sessions after DELETE, got %d", s.SessionCount())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp = postMCP(t, s, id, "application/json", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 after DELETE, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableIdleSessionExpires(t *testing.T) {
+	s := startStreamableWith(t, intTransport.HTTPOptions{SessionIdleTimeout: 50 * time.Millisecond})
+	id := initialize(t, s)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.SessionCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected idle session to expire, still have %d", s.SessionCount())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	resp := postMCP(t, s, id, "application/json", `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 after expiry, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableOpenStreamKeepsSession(t *testing.T) {
+	s := startStreamableWith(t, intTransport.HTTPOptions{SessionIdleTimeout: 50 * time.Millisecond})
+	id := initialize(t, s)
+
+	stream := getMCP(t, s, id, "")
+	time.Sleep(200 * time.Millisecond)
+
+	if n := s.SessionCount(); n != 1 {
+		t.Fatalf("session with an open stream expired: %d sessions", n)
+	}
+
+	stream.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for s.SessionCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected session to expire once its stream closed, still have %d", s.SessionCount())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamableMaxSessions(t *testing.T) {
+	s := startStreamableWith(t, intTransport.HTTPOptions{MaxSessions: 1})
+	initialize(t, s)
+
+	resp := postMCP(t, s, "", "application/json",
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{}}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 beyond the session cap, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamableNotAcceptable(t *testing.T) {
+	s := startStreamable(t)
+
+	resp := postMCP(t, s, "", "text/html", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("expected 406, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,76 @@
+#! /usr/bin/env bats
+
+setup() {
+  load "$(dirname "$BATS_TEST_FILE")/common.bash"
+  export output
+  export GRIT_BIN="$BATS_TEST_DIRNAME/../result/bin/grit"
+}
+
+teardown() {
+  chflags_and_rm
+}
+
+function mcp_commit_reports_full_hash_and_stats { # @test
+  setup_test_repo
+  echo "changed" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" add file.txt
+  run run_grit_mcp "commit" "$(printf '{"repo_path":"%s","message":"change file"}' "$TEST_REPO")"
+  assert_success
+  local hash additions
+  hash=$(echo "$output" | jq -r '.hash')
+  additions=$(echo "$output" | jq -r '.summary.total_additions')
+  assert_equal "$hash" "$(git -C "$TEST_REPO" rev-parse HEAD)"
+  assert_equal "$additions" "1"
+}
+
+function mcp_commit_amend_keeps_message { # @test
+  setup_test_repo
+  echo "more" >> "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" add file.txt
+  run run_grit_mcp "commit" "$(printf '{"repo_path":"%s","amend":true}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "amended"
+
+  run git -C "$TEST_REPO" log --format=%s
+  assert_output "initial commit"
+}
+
+function mcp_commit_amend_blocked_when_pushed { # @test
+  setup_test_repo
+  git init --bare "$BATS_TEST_TMPDIR/remote.git"
+  git -C "$TEST_REPO" remote add origin "$BATS_TEST_TMPDIR/remote.git"
+  git -C "$TEST_REPO" push -u origin main
+  run run_grit_mcp "commit" "$(printf '{"repo_path":"%s","amend":true,"message":"rewritten"}' "$TEST_REPO")"
+  assert_success
+  assert_output --partial "already pushed"
+
+  run run_grit_mcp "commit" "$(printf '{"repo_path":"%s","amend":true,"message":"rewritten","allow_pushed":true}' "$TEST_REPO")"
+  assert_success
+  run git -C "$TEST_REPO" log -1 --format=%s
+  assert_output "rewritten"
+}
+
+function mcp_commit_fixup_with_paths { # @test
+  setup_test_repo
+  echo "a" > "$TEST_REPO/a.txt"
+  echo "b" > "$TEST_REPO/b.txt"
+  git -C "$TEST_REPO" add a.txt b.txt
+  run run_grit_mcp "commit" "$(printf '{"repo_path":"%s","fixup":"HEAD","paths":["a.txt"]}' "$TEST_REPO")"
+  assert_success
+  local subject
+  subject=$(echo "$output" | jq -r '.subject')
+  assert_equal "$subject" "fixup! initial commit"
+
+  run git -C "$TEST_REPO" diff --cached --name-only
+  assert_output "b.txt"
+}
+
+function mcp_commit_trailers { # @test
+  setup_test_repo
+  run run_grit_mcp "commit" "$(printf '{"repo_path":"%s","message":"empty","allow_empty":true,"trailers":["Signed-off-by: Test User <test@example.com>"]}' "$TEST_REPO")"
+  assert_success
+  run git -C "$TEST_REPO" log -1 --format=%b
+  assert_output "Signed-off-by: Test User <test@example.com>"
+}
@@ -0,0 +1,93 @@
+#! /usr/bin/env bats
+
+setup() {
+  load "$(dirname "$BATS_TEST_FILE")/common.bash"
+  export output
+  export GRIT_BIN="$BATS_TEST_DIRNAME/../result/bin/grit"
+}
+
+teardown() {
+  chflags_and_rm
+}
+
+function mcp_clean_merge { # @test
+  setup_clean_rebase_scenario
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"main"}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "merged"
+  assert [ -f "$TEST_REPO/file_a.txt" ]
+  assert [ -f "$TEST_REPO/file_b.txt" ]
+}
+
+function mcp_merge_with_conflicts_returns_conflict_status { # @test
+  setup_conflict_scenario
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"main"}' "$TEST_REPO")"
+  assert_success
+  local status conflicts
+  status=$(echo "$output" | jq -r '.status')
+  conflicts=$(echo "$output" | jq -r '.conflicts[]')
+  assert_equal "$status" "conflict"
+  assert_equal "$conflicts" "file.txt"
+}
+
+function mcp_merge_continue_after_resolving { # @test
+  setup_conflict_scenario
+  run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"main"}' "$TEST_REPO")"
+
+  echo "resolved" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" add file.txt
+
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","continue":true}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "merged"
+}
+
+function mcp_merge_abort { # @test
+  setup_conflict_scenario
+  run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"main"}' "$TEST_REPO")"
+
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","abort":true}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "aborted"
+  run cat "$TEST_REPO/file.txt"
+  assert_output "feature change"
+}
+
+function mcp_merge_fast_forward_on_main { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" checkout -b feature
+  echo "feature" > "$TEST_REPO/file_b.txt"
+  git -C "$TEST_REPO" add file_b.txt
+  git -C "$TEST_REPO" commit -m "feature: add file_b"
+  git -C "$TEST_REPO" checkout main
+
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"feature","ff_only":true}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "fast_forward"
+}
+
+function mcp_merge_blocked_on_main_without_ff_only { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" branch feature
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"feature"}' "$TEST_REPO")"
+  assert_success
+  assert_output --partial "only allowed with ff_only"
+}
+
+function mcp_merge_up_to_date { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" checkout -b feature
+  run run_grit_mcp "merge" "$(printf '{"repo_path":"%s","ref":"main"}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "up_to_date"
+}
@@ -91,3 +91,55 @@ function mcp_rebase_blocked_on_main { # @test
   # Should be an error result
   assert_output --partial "blocked"
 }
+
+function mcp_rebase_plan_reword_and_squash { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" checkout -b feature
+  echo "a" > "$TEST_REPO/a.txt"
+  git -C "$TEST_REPO" add a.txt
+  git -C "$TEST_REPO" commit -m "add a"
+  echo "b" > "$TEST_REPO/b.txt"
+  git -C "$TEST_REPO" add b.txt
+  git -C "$TEST_REPO" commit -m "add b"
+  local a b
+  a=$(git -C "$TEST_REPO" rev-parse HEAD~1)
+  b=$(git -C "$TEST_REPO" rev-parse HEAD)
+
+  run run_grit_mcp "rebase" "$(printf '{"repo_path":"%s","upstream":"main","rebase_plan":["reword %s add files","fixup %s"]}' "$TEST_REPO" "$a" "$b")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "completed"
+
+  run git -C "$TEST_REPO" log --format=%s main..feature
+  assert_output "add files"
+}
+
+function mcp_rebase_plan_requires_every_commit { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" checkout -b feature
+  git -C "$TEST_REPO" commit --allow-empty -m "one"
+  git -C "$TEST_REPO" commit --allow-empty -m "two"
+  run run_grit_mcp "rebase" "$(printf '{"repo_path":"%s","upstream":"main","rebase_plan":["pick HEAD"]}' "$TEST_REPO")"
+  assert_success
+  assert_output --partial "missing commits"
+}
+
+function mcp_rebase_autosquash { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" checkout -b feature
+  echo "a" > "$TEST_REPO/a.txt"
+  git -C "$TEST_REPO" add a.txt
+  git -C "$TEST_REPO" commit -m "add a"
+  echo "b" > "$TEST_REPO/b.txt"
+  git -C "$TEST_REPO" add b.txt
+  git -C "$TEST_REPO" commit -m "add b"
+  echo "a2" >> "$TEST_REPO/a.txt"
+  git -C "$TEST_REPO" commit -am "fixup! add a"
+
+  run run_grit_mcp "rebase" "$(printf '{"repo_path":"%s","upstream":"main","autosquash":true}' "$TEST_REPO")"
+  assert_success
+  run git -C "$TEST_REPO" log --format=%s main..feature
+  assert_output "add b
+add a"
+}
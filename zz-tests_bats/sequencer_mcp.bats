@@ -0,0 +1,77 @@
+#! /usr/bin/env bats
+
+setup() {
+  load "$(dirname "$BATS_TEST_FILE")/common.bash"
+  export output
+  export GRIT_BIN="$BATS_TEST_DIRNAME/../result/bin/grit"
+}
+
+teardown() {
+  chflags_and_rm
+}
+
+function mcp_clean_cherry_pick { # @test
+  setup_clean_rebase_scenario
+  run run_grit_mcp "cherry_pick" "$(printf '{"repo_path":"%s","commits":["main"],"record_origin":true}' "$TEST_REPO")"
+  assert_success
+  local status subject
+  status=$(echo "$output" | jq -r '.status')
+  subject=$(echo "$output" | jq -r '.applied[0].subject')
+  assert_equal "$status" "completed"
+  assert_equal "$subject" "main: add file_a"
+  run git -C "$TEST_REPO" log -1 --format=%B
+  assert_output --partial "cherry picked from commit"
+}
+
+function mcp_cherry_pick_with_conflicts_returns_conflict_status { # @test
+  setup_conflict_scenario
+  run run_grit_mcp "cherry_pick" "$(printf '{"repo_path":"%s","commits":["main"]}' "$TEST_REPO")"
+  assert_success
+  local status conflicts current main_hash
+  status=$(echo "$output" | jq -r '.status')
+  conflicts=$(echo "$output" | jq -r '.conflicts[]')
+  current=$(echo "$output" | jq -r '.current_commit')
+  main_hash=$(git -C "$TEST_REPO" rev-parse main)
+  assert_equal "$status" "conflict"
+  assert_equal "$conflicts" "file.txt"
+  assert_equal "$current" "$main_hash"
+}
+
+function mcp_cherry_pick_continue_after_resolving { # @test
+  setup_conflict_scenario
+  run_grit_mcp "cherry_pick" "$(printf '{"repo_path":"%s","commits":["main"]}' "$TEST_REPO")"
+
+  echo "resolved" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" add file.txt
+
+  run run_grit_mcp "cherry_pick" "$(printf '{"repo_path":"%s","continue":true}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "completed"
+}
+
+function mcp_cherry_pick_abort { # @test
+  setup_conflict_scenario
+  run_grit_mcp "cherry_pick" "$(printf '{"repo_path":"%s","commits":["main"]}' "$TEST_REPO")"
+
+  run run_grit_mcp "cherry_pick" "$(printf '{"repo_path":"%s","abort":true}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "aborted"
+}
+
+function mcp_revert_commit { # @test
+  setup_test_repo
+  echo "second" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" commit -am "second"
+
+  run run_grit_mcp "revert" "$(printf '{"repo_path":"%s","commits":["HEAD"]}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "completed"
+  run cat "$TEST_REPO/file.txt"
+  assert_output "initial"
+}
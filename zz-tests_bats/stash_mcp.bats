@@ -0,0 +1,94 @@
+#! /usr/bin/env bats
+
+setup() {
+  load "$(dirname "$BATS_TEST_FILE")/common.bash"
+  export output
+  export GRIT_BIN="$BATS_TEST_DIRNAME/../result/bin/grit"
+}
+
+teardown() {
+  chflags_and_rm
+}
+
+function mcp_stash_push_and_list { # @test
+  setup_test_repo
+  echo "modified" > "$TEST_REPO/file.txt"
+  run run_grit_mcp "stash_push" "$(printf '{"repo_path":"%s","message":"wip"}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "stashed"
+
+  run run_grit_mcp "stash_list" "$(printf '{"repo_path":"%s"}' "$TEST_REPO")"
+  assert_success
+  local message branch
+  message=$(echo "$output" | jq -r '.stashes[0].message')
+  branch=$(echo "$output" | jq -r '.stashes[0].branch')
+  assert_equal "$message" "wip"
+  assert_equal "$branch" "main"
+}
+
+function mcp_stash_push_without_changes { # @test
+  setup_test_repo
+  run run_grit_mcp "stash_push" "$(printf '{"repo_path":"%s"}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "no_changes"
+}
+
+function mcp_stash_push_include_untracked { # @test
+  setup_test_repo
+  echo "new" > "$TEST_REPO/untracked.txt"
+  run run_grit_mcp "stash_push" "$(printf '{"repo_path":"%s","include_untracked":true}' "$TEST_REPO")"
+  assert_success
+  assert [ ! -f "$TEST_REPO/untracked.txt" ]
+}
+
+function mcp_stash_pop_restores_changes { # @test
+  setup_test_repo
+  echo "modified" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" stash push
+
+  run run_grit_mcp "stash_pop" "$(printf '{"repo_path":"%s"}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "popped"
+  run cat "$TEST_REPO/file.txt"
+  assert_output "modified"
+}
+
+function mcp_stash_pop_with_conflicts_returns_conflict_status { # @test
+  setup_test_repo
+  echo "stashed change" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" stash push
+  echo "committed change" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" commit -am "conflicting change"
+
+  run run_grit_mcp "stash_pop" "$(printf '{"repo_path":"%s"}' "$TEST_REPO")"
+  assert_success
+  local status conflicts
+  status=$(echo "$output" | jq -r '.status')
+  conflicts=$(echo "$output" | jq -r '.conflicts[]')
+  assert_equal "$status" "conflict"
+  assert_equal "$conflicts" "file.txt"
+
+  # The entry is kept when pop conflicts
+  run git -C "$TEST_REPO" stash list
+  assert_output --partial "stash@{0}"
+}
+
+function mcp_stash_drop { # @test
+  setup_test_repo
+  echo "modified" > "$TEST_REPO/file.txt"
+  git -C "$TEST_REPO" stash push
+
+  run run_grit_mcp "stash_drop" "$(printf '{"repo_path":"%s","index":0}' "$TEST_REPO")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "dropped"
+  run git -C "$TEST_REPO" stash list
+  assert_output ""
+}
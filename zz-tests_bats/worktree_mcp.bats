@@ -0,0 +1,69 @@
+#! /usr/bin/env bats
+
+setup() {
+  load "$(dirname "$BATS_TEST_FILE")/common.bash"
+  export output
+  export GRIT_BIN="$BATS_TEST_DIRNAME/../result/bin/grit"
+}
+
+teardown() {
+  chflags_and_rm
+}
+
+function mcp_worktree_add_new_branch { # @test
+  setup_test_repo
+  export GRIT_WORKTREE_ROOT="$BATS_TEST_TMPDIR/worktrees"
+  run run_grit_mcp "worktree_add" "$(printf '{"repo_path":"%s","new_branch":"agent/task"}' "$TEST_REPO")"
+  assert_success
+  local status path
+  status=$(echo "$output" | jq -r '.status')
+  path=$(echo "$output" | jq -r '.path')
+  assert_equal "$status" "added"
+  assert_equal "$path" "$GRIT_WORKTREE_ROOT/agent-task"
+  assert [ -f "$GRIT_WORKTREE_ROOT/agent-task/file.txt" ]
+}
+
+function mcp_worktree_add_rejects_path_outside_root { # @test
+  setup_test_repo
+  export GRIT_WORKTREE_ROOT="$BATS_TEST_TMPDIR/worktrees"
+  run run_grit_mcp "worktree_add" "$(printf '{"repo_path":"%s","detach":true,"path":"../escape"}' "$TEST_REPO")"
+  assert_success
+  assert_output --partial "must be inside the worktree root"
+}
+
+function mcp_worktree_list { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" worktree add -b feature "$BATS_TEST_TMPDIR/feature"
+  run run_grit_mcp "worktree_list" "$(printf '{"repo_path":"%s"}' "$TEST_REPO")"
+  assert_success
+  local branch
+  branch=$(echo "$output" | jq -r '.[1].branch')
+  assert_equal "$branch" "feature"
+}
+
+function mcp_worktree_remove_refuses_dirty { # @test
+  setup_test_repo
+  git -C "$TEST_REPO" worktree add -b feature "$BATS_TEST_TMPDIR/feature"
+  echo "dirty" > "$BATS_TEST_TMPDIR/feature/file.txt"
+  run run_grit_mcp "worktree_remove" "$(printf '{"repo_path":"%s","path":"%s"}' "$TEST_REPO" "$BATS_TEST_TMPDIR/feature")"
+  assert_success
+  assert_output --partial "uncommitted changes"
+  assert [ -d "$BATS_TEST_TMPDIR/feature" ]
+
+  run run_grit_mcp "worktree_remove" "$(printf '{"repo_path":"%s","path":"%s","force":true}' "$TEST_REPO" "$BATS_TEST_TMPDIR/feature")"
+  assert_success
+  local status
+  status=$(echo "$output" | jq -r '.status')
+  assert_equal "$status" "removed"
+}
+
+function mcp_rebase_in_progress_detected_in_worktree { # @test
+  setup_conflict_scenario
+  git -C "$TEST_REPO" checkout main
+  git -C "$TEST_REPO" worktree add "$BATS_TEST_TMPDIR/feature" feature
+  run_grit_mcp "rebase" "$(printf '{"repo_path":"%s","upstream":"main"}' "$BATS_TEST_TMPDIR/feature")"
+
+  run run_grit_mcp "rebase" "$(printf '{"repo_path":"%s","upstream":"main"}' "$BATS_TEST_TMPDIR/feature")"
+  assert_success
+  assert_output --partial "already in progress"
+}